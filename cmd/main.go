@@ -1,14 +1,26 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+
 	"github.com/GabrielMoody/mikronet-dashboard-service/internal/handler"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/middleware"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/migrations"
 	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
 func main() {
+	helper.RegisterQueryTypes()
+
 	app := fiber.New(fiber.Config{
 		StrictRouting: true,
 		ServerHeader:  "mikronet.systems",
@@ -22,10 +34,19 @@ func main() {
 		return c.Next()
 	})
 
+	app.Use(func(c *fiber.Ctx) error {
+		// Reads after a write in the same request skip the read replicas.
+		c.SetUserContext(helper.WithWriteTracking(c.UserContext()))
+		return c.Next()
+	})
+
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowHeaders: "*",
 		AllowMethods: "*",
+		// Browsers only hand the ETag to scripts when it is exposed, and
+		// updates and deletes have to send it back in If-Match.
+		ExposeHeaders: fiber.HeaderETag,
 	}))
 
 	app.Use(logger.New(logger.Config{
@@ -35,11 +56,100 @@ func main() {
 
 	db := models.DatabaseInit()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := migrator.Check(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := models.SeedRoles(db); err != nil {
+		log.Fatal(err)
+	}
+
+	replicaConfig, err := models.ReplicaConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(replicaConfig.Addrs) > 0 {
+		replicas, err := models.UseReplicas(db, replicaConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go replicas.Run(context.Background())
+	}
+
+	verifier, err := middleware.NewVerifierFromEnv(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	approvals, err := service.ApprovalConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cache, err := service.CacheConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	purge, err := service.PurgeConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go service.NewPurgeJob(repository.NewPurgeRepo(db), purge).Run(context.Background())
+
+	fares, err := service.FareConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	reports, err := service.ReportConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// DEMO_MODE keeps drivers, passengers, routes and trips in memory, seeded
+	// with sample data. Search, admins, API keys and the audit log still use the
+	// database.
+	dashboardRepo := repository.NewDashboardRepo(db)
+	if demo, _ := strconv.ParseBool(os.Getenv("DEMO_MODE")); demo {
+		log.Print("DEMO_MODE is on, dashboard data is kept in memory and reset on restart")
+		dashboardRepo = repository.NewMemoryDashboardRepo(repository.DemoData())
+	}
+
+	serviceDashboard := service.NewDashboardService(dashboardRepo, reports)
+
+	var dashboardCache *service.DashboardCache
+	if cache.Store != nil {
+		dashboardCache = service.NewDashboardCache(serviceDashboard, cache)
+		serviceDashboard = dashboardCache
+	}
+
+	go service.NewFareJob(serviceDashboard, fares).Run(context.Background())
+
 	api := app.Group("/")
 
-	handler.DashboardHandler(api, db)
+	handler.DashboardHandler(api, db, serviceDashboard, verifier, approvals, reports, dashboardCache)
+
+	if err := handler.DashboardPolicies.Verify(app.GetRoutes(true)); err != nil {
+		log.Fatal(err)
+	}
 
-	err := app.Listen("0.0.0.0:8030")
+	err = app.Listen("0.0.0.0:8030")
 	if err != nil {
 		return
 	}
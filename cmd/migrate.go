@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/migrations"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate implements the migrate subcommand.
+func runMigrate(c context.Context, db *gorm.DB, args []string) error {
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(c)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+
+		reverted, err := migrator.Down(c, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+
+	case "status":
+		status, err := migrator.Status(c)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+		for _, s := range status {
+			state, at := "pending", ""
+			switch {
+			case s.Dirty:
+				state = "dirty"
+			case s.Applied:
+				state = "applied"
+			}
+			if s.AppliedAt != nil {
+				at = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, at)
+		}
+		return w.Flush()
+
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,16 @@
+// Package cache holds the key-value stores the service caches query results
+// in: an in-process LRU and a Redis backed store shared by every instance.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps byte values for a while. A ttl of zero keeps the value until it
+// is overwritten or evicted.
+type Store interface {
+	Get(c context.Context, key string) (value []byte, ok bool, err error)
+	Set(c context.Context, key string, value []byte, ttl time.Duration) error
+	Name() string
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// LRU is an in-process Store that drops the least recently used entry once
+// it holds size entries. Other instances cannot invalidate what it holds, so
+// it only suits a service running as a single instance.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func (a *LRU) Get(c context.Context, key string) ([]byte, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	el, ok := a.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := el.Value.(*lruEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		a.order.Remove(el)
+		delete(a.entries, key)
+		return nil, false, nil
+	}
+
+	a.order.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+func (a *LRU) Set(c context.Context, key string, value []byte, ttl time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if el, ok := a.entries[key]; ok {
+		el.Value = &lruEntry{key: key, value: value, expires: expires}
+		a.order.MoveToFront(el)
+		return nil
+	}
+
+	a.entries[key] = a.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+
+	for a.order.Len() > a.size {
+		oldest := a.order.Back()
+		a.order.Remove(oldest)
+		delete(a.entries, oldest.Value.(*lruEntry).key)
+	}
+
+	return nil
+}
+
+func (a *LRU) Name() string {
+	return "lru"
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Store on any server speaking the Redis protocol, so the cache is
+// shared by every instance of the service. Keys are prefixed to keep them
+// apart from other users of the same server.
+type Redis struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func (a *Redis) Get(c context.Context, key string) ([]byte, bool, error) {
+	value, err := a.client.Get(c, a.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (a *Redis) Set(c context.Context, key string, value []byte, ttl time.Duration) error {
+	return a.client.Set(c, a.prefix+key, value, ttl).Err()
+}
+
+func (a *Redis) Name() string {
+	return "redis"
+}
+
+func NewRedis(client redis.UniversalClient, prefix string) *Redis {
+	return &Redis{
+		client: client,
+		prefix: prefix,
+	}
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/middleware"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminController interface {
+	GetRoles(c *fiber.Ctx) error
+	SetAdminRole(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+	RevokeSessions(c *fiber.Ctx) error
+}
+
+type AdminControllerImpl struct {
+	AdminService service.AdminService
+}
+
+func (a *AdminControllerImpl) GetRoles(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	res, err := a.AdminService.GetRoles(ctx)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *AdminControllerImpl) SetAdminRole(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	var body dto.SetRole
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	_, err := a.AdminService.SetAdminRole(ctx, id, body)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "Success",
+		"message": "Berhasil mengubah role admin!",
+	})
+}
+
+func (a *AdminControllerImpl) Logout(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	claims := middleware.ClaimsFrom(c)
+
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	res, err := a.AdminService.Logout(ctx, claims.ID, claims.AdminID(), expiresAt)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *AdminControllerImpl) RevokeSessions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	res, err := a.AdminService.RevokeSessions(ctx, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func NewAdminController(service service.AdminService) AdminController {
+	return &AdminControllerImpl{AdminService: service}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/middleware"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ApiKeyController interface {
+	CreateApiKey(c *fiber.Ctx) error
+	GetApiKeys(c *fiber.Ctx) error
+	RevokeApiKey(c *fiber.Ctx) error
+	RotateApiKey(c *fiber.Ctx) error
+}
+
+type ApiKeyControllerImpl struct {
+	ApiKeyService service.ApiKeyService
+}
+
+func (a *ApiKeyControllerImpl) CreateApiKey(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var body dto.CreateApiKey
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	res, err := a.ApiKeyService.CreateApiKey(ctx, middleware.ClaimsFrom(c).AdminID(), body)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *ApiKeyControllerImpl) GetApiKeys(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	res, err := a.ApiKeyService.GetApiKeys(ctx)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data": fiber.Map{
+			"api_keys": res,
+			"count":    len(res),
+		},
+	})
+}
+
+func (a *ApiKeyControllerImpl) RevokeApiKey(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	res, err := a.ApiKeyService.RevokeApiKey(ctx, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *ApiKeyControllerImpl) RotateApiKey(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	res, err := a.ApiKeyService.RotateApiKey(ctx, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func NewApiKeyController(service service.ApiKeyService) ApiKeyController {
+	return &ApiKeyControllerImpl{ApiKeyService: service}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditController interface {
+	GetAuditLogs(c *fiber.Ctx) error
+}
+
+type AuditControllerImpl struct {
+	AuditService service.AuditService
+}
+
+func (a *AuditControllerImpl) GetAuditLogs(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var q dto.AuditQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err.Error(),
+		})
+	}
+
+	res, total, err := a.AuditService.GetAuditLogs(ctx, q)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data": fiber.Map{
+			"audit_logs": res,
+			"count":      len(res),
+			"total":      total,
+		},
+	})
+}
+
+func NewAuditController(service service.AuditService) AuditController {
+	return &AuditControllerImpl{AuditService: service}
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CacheController interface {
+	GetCacheStats(c *fiber.Ctx) error
+}
+
+type CacheControllerImpl struct {
+	DashboardCache *service.DashboardCache
+}
+
+func (a *CacheControllerImpl) GetCacheStats(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	if a.DashboardCache == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"status": "error",
+			"errors": "cache is off",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   a.DashboardCache.Stats(ctx),
+	})
+}
+
+func NewCacheController(cache *service.DashboardCache) CacheController {
+	return &CacheControllerImpl{DashboardCache: cache}
+}
@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"context"
 	"net/http"
 	"os"
 
 	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
 	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,28 +21,64 @@ type DashboardController interface {
 	EditAmountRoute(c *fiber.Ctx) error
 	DeleteDriver(c *fiber.Ctx) error
 	DeleteUser(c *fiber.Ctx) error
+	RestoreDriver(c *fiber.Ctx) error
+	RestoreUser(c *fiber.Ctx) error
 	BlockAccount(c *fiber.Ctx) error
 	UnblockAccount(c *fiber.Ctx) error
 	GetReviews(c *fiber.Ctx) error
 	GetReviewByID(c *fiber.Ctx) error
 	GetAllBlockAccount(c *fiber.Ctx) error
 	AddRoute(c *fiber.Ctx) error
-	MonthlyReport(c *fiber.Ctx) error
+	GetReport(c *fiber.Ctx) error
+	GetDriverReport(c *fiber.Ctx) error
+	GetDriverEarnings(c *fiber.Ctx) error
+	GetDemand(c *fiber.Ctx) error
 	GetKTP(c *fiber.Ctx) error
 	GetRoutes(c *fiber.Ctx) error
+	GetRouteByID(c *fiber.Ctx) error
 	DeleteRoute(c *fiber.Ctx) error
+	GetRouteFares(c *fiber.Ctx) error
+	ScheduleRouteFare(c *fiber.Ctx) error
+	CancelRouteFare(c *fiber.Ctx) error
 }
 
 type DashboardControllerImpl struct {
 	DashboardService service.DashboardService
+	ProposalService  service.ProposalService
 }
 
 func (a *DashboardControllerImpl) DeleteRoute(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	id := c.Params("id")
 
-	res, err := a.DashboardService.DeleteRoute(ctx, id)
+	version, ok := ifMatch(c)
+	if !ok {
+		return preconditionFailed(c)
+	}
+	if version == 0 {
+		return preconditionRequired(c)
+	}
+
+	if a.ProposalService.Requires(service.ActionDeleteRoute) {
+		if err := a.checkRouteVersion(ctx, id, version); err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+
+		res, err := a.ProposalService.Propose(ctx, service.ActionDeleteRoute, id, dto.Precondition{Version: version})
+		if err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+		return proposed(c, res)
+	}
+
+	res, err := a.DashboardService.DeleteRoute(ctx, id, version)
 
 	if err != nil {
 		return c.Status(err.Code).JSON(fiber.Map{
@@ -56,7 +94,7 @@ func (a *DashboardControllerImpl) DeleteRoute(c *fiber.Ctx) error {
 }
 
 func (a *DashboardControllerImpl) GetRoutes(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	res, err := a.DashboardService.GetRoutes(ctx)
 
@@ -73,8 +111,118 @@ func (a *DashboardControllerImpl) GetRoutes(c *fiber.Ctx) error {
 	})
 }
 
+func (a *DashboardControllerImpl) GetRouteByID(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	res, err := a.DashboardService.GetRouteById(ctx, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	setETag(c, res.Version)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *DashboardControllerImpl) GetRouteFares(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	res, err := a.DashboardService.GetRouteFares(ctx, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *DashboardControllerImpl) ScheduleRouteFare(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	var b dto.ScheduleFare
+	if err := c.BodyParser(&b); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	if a.ProposalService.Requires(service.ActionScheduleFare) {
+		res, err := a.ProposalService.Propose(ctx, service.ActionScheduleFare, id, b)
+		if err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+		return proposed(c, res)
+	}
+
+	res, err := a.DashboardService.ScheduleRouteFare(ctx, id, b)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "Success",
+		"message": "Berhasil menjadwalkan harga!",
+		"data":    res,
+	})
+}
+
+func (a *DashboardControllerImpl) CancelRouteFare(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	if a.ProposalService.Requires(service.ActionCancelFare) {
+		res, err := a.ProposalService.Propose(ctx, service.ActionCancelFare, id, dto.CancelFare{FareID: c.Params("fareId")})
+		if err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+		return proposed(c, res)
+	}
+
+	res, err := a.DashboardService.CancelRouteFare(ctx, id, c.Params("fareId"))
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "Success",
+		"message": "Berhasil membatalkan harga!",
+		"data":    res,
+	})
+}
+
 func (a *DashboardControllerImpl) GetKTP(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	id := c.Params("id")
 
 	res, err := a.DashboardService.GetImage(ctx, id)
@@ -103,9 +251,17 @@ func (a *DashboardControllerImpl) GetKTP(c *fiber.Ctx) error {
 }
 
 func (a *DashboardControllerImpl) GetAllTripHistories(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
+
+	q, errQuery := parseListQuery(c)
+	if errQuery != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": errQuery.Error(),
+		})
+	}
 
-	res, err := a.DashboardService.GetAllHistories(ctx)
+	res, page, err := a.DashboardService.GetAllHistories(ctx, q)
 
 	if err != nil {
 		return c.Status(err.Code).JSON(fiber.Map{
@@ -116,12 +272,17 @@ func (a *DashboardControllerImpl) GetAllTripHistories(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "Success",
-		"data":   res,
+		"data": fiber.Map{
+			"histories":   res,
+			"count":       len(res),
+			"next_cursor": page.NextCursor,
+			"has_more":    page.HasMore,
+		},
 	})
 }
 
 func (a *DashboardControllerImpl) EditAmountRoute(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	id := c.Params("id")
 
 	var b dto.EditAmount
@@ -132,7 +293,88 @@ func (a *DashboardControllerImpl) EditAmountRoute(c *fiber.Ctx) error {
 		})
 	}
 
-	_, err := a.DashboardService.EditAmountRoute(ctx, b, id)
+	var ok bool
+	if b.Version, ok = ifMatch(c); !ok {
+		return preconditionFailed(c)
+	}
+	if b.Version == 0 {
+		return preconditionRequired(c)
+	}
+
+	if a.ProposalService.Requires(service.ActionUpdateRoute) {
+		if err := a.checkRouteVersion(ctx, id, b.Version); err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+
+		res, err := a.ProposalService.Propose(ctx, service.ActionUpdateRoute, id, b)
+		if err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+		return proposed(c, res)
+	}
+
+	res, err := a.DashboardService.EditAmountRoute(ctx, b, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	setETag(c, res.Version)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "Success",
+		"message": "Berhasil memperbaharui harga!",
+		"data":    res,
+	})
+}
+
+func (a *DashboardControllerImpl) GetReport(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var q dto.ReportQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	res, err := a.DashboardService.Report(ctx, q)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *DashboardControllerImpl) GetDriverReport(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var q dto.DriverReportQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	res, err := a.DashboardService.DriverReport(ctx, q)
 
 	if err != nil {
 		return c.Status(err.Code).JSON(fiber.Map{
@@ -143,14 +385,15 @@ func (a *DashboardControllerImpl) EditAmountRoute(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "Success",
-		"data":   "Berhasil memperbaharui harga!",
+		"data":   res,
 	})
 }
 
-func (a *DashboardControllerImpl) MonthlyReport(c *fiber.Ctx) error {
-	ctx := c.Context()
+func (a *DashboardControllerImpl) GetDriverEarnings(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
 
-	var q dto.MonthReport
+	var q dto.DriverReportQuery
 	if err := c.QueryParser(&q); err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"status": "error",
@@ -158,7 +401,33 @@ func (a *DashboardControllerImpl) MonthlyReport(c *fiber.Ctx) error {
 		})
 	}
 
-	res, err := a.DashboardService.MonthlyReport(ctx, q)
+	res, err := a.DashboardService.DriverEarnings(ctx, id, q)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *DashboardControllerImpl) GetDemand(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var q dto.DemandQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	res, err := a.DashboardService.Demand(ctx, q)
 
 	if err != nil {
 		return c.Status(err.Code).JSON(fiber.Map{
@@ -174,7 +443,7 @@ func (a *DashboardControllerImpl) MonthlyReport(c *fiber.Ctx) error {
 }
 
 func (a *DashboardControllerImpl) AddRoute(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	var body dto.AddRoute
 	if err := c.BodyParser(&body); err != nil {
@@ -193,6 +462,8 @@ func (a *DashboardControllerImpl) AddRoute(c *fiber.Ctx) error {
 		})
 	}
 
+	setETag(c, res.Version)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "Success",
 		"data":   res,
@@ -200,12 +471,20 @@ func (a *DashboardControllerImpl) AddRoute(c *fiber.Ctx) error {
 }
 
 func (a *DashboardControllerImpl) GetAllBlockAccount(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 
-	res, err := a.DashboardService.GetAllBlockAccount(ctx)
+	q, errQuery := parseListQuery(c)
+	if errQuery != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": errQuery.Error(),
+		})
+	}
+
+	res, page, err := a.DashboardService.GetAllBlockAccount(ctx, q)
 
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
@@ -216,18 +495,46 @@ func (a *DashboardControllerImpl) GetAllBlockAccount(c *fiber.Ctx) error {
 		"data": fiber.Map{
 			"block_accounts": res,
 			"count":          len(res),
+			"next_cursor":    page.NextCursor,
+			"has_more":       page.HasMore,
 		},
 	})
 }
 
 func (a *DashboardControllerImpl) DeleteDriver(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	id := c.Params("id")
 
-	_, err := a.DashboardService.DeleteDriver(ctx, id)
+	version, ok := ifMatch(c)
+	if !ok {
+		return preconditionFailed(c)
+	}
+	if version == 0 {
+		return preconditionRequired(c)
+	}
+
+	if a.ProposalService.Requires(service.ActionDeleteDriver) {
+		if err := a.checkDriverVersion(ctx, id, version); err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+
+		res, err := a.ProposalService.Propose(ctx, service.ActionDeleteDriver, id, dto.Precondition{Version: version})
+		if err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+		return proposed(c, res)
+	}
+
+	res, err := a.DashboardService.DeleteDriver(ctx, id, version)
 
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
@@ -236,17 +543,29 @@ func (a *DashboardControllerImpl) DeleteDriver(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":  "Success",
 		"message": "Berhasil menghapus akun!",
+		"data":    res,
 	})
 }
 
 func (a *DashboardControllerImpl) DeleteUser(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	id := c.Params("id")
 
-	_, err := a.DashboardService.DeleteUser(ctx, id)
+	if a.ProposalService.Requires(service.ActionDeletePassenger) {
+		res, err := a.ProposalService.Propose(ctx, service.ActionDeletePassenger, id, nil)
+		if err != nil {
+			return c.Status(err.Code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+		return proposed(c, res)
+	}
+
+	res, err := a.DashboardService.DeleteUser(ctx, id)
 
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
@@ -255,30 +574,42 @@ func (a *DashboardControllerImpl) DeleteUser(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":  "Success",
 		"message": "Berhasil menghapus akun!",
+		"data":    res,
 	})
 }
 
 func (a *DashboardControllerImpl) SetDriverStatusVerified(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	id := c.Params("id")
 
-	res, err := a.DashboardService.SetDriverStatusVerified(ctx, id)
+	version, ok := ifMatch(c)
+	if !ok {
+		return preconditionFailed(c)
+	}
+	if version == 0 {
+		return preconditionRequired(c)
+	}
+
+	res, err := a.DashboardService.SetDriverStatusVerified(ctx, id, version)
 
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
 	}
 
+	setETag(c, res.Version)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"status": "Success",
-		"data":   res,
+		"status":  "Success",
+		"message": "Berhasil memverifikasi driver",
+		"data":    res,
 	})
 }
 
 func (a *DashboardControllerImpl) UnblockAccount(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	accountId := c.Params("id")
 
 	_, err := a.DashboardService.UnblockAccount(ctx, accountId)
@@ -296,13 +627,59 @@ func (a *DashboardControllerImpl) UnblockAccount(c *fiber.Ctx) error {
 	})
 }
 
+func (a *DashboardControllerImpl) RestoreDriver(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	res, err := a.DashboardService.RestoreDriver(ctx, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "Success",
+		"message": res,
+	})
+}
+
+func (a *DashboardControllerImpl) RestoreUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	res, err := a.DashboardService.RestoreUser(ctx, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "Success",
+		"message": res,
+	})
+}
+
 func (a *DashboardControllerImpl) GetReviews(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
+
+	q, errQuery := parseListQuery(c)
+	if errQuery != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": errQuery.Error(),
+		})
+	}
 
-	res, err := a.DashboardService.GetAllReviews(ctx)
+	res, page, err := a.DashboardService.GetAllReviews(ctx, q)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
@@ -311,20 +688,22 @@ func (a *DashboardControllerImpl) GetReviews(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "Success",
 		"data": fiber.Map{
-			"reviews": res,
-			"count":   len(res),
+			"reviews":     res,
+			"count":       len(res),
+			"next_cursor": page.NextCursor,
+			"has_more":    page.HasMore,
 		},
 	})
 }
 
 func (a *DashboardControllerImpl) GetReviewByID(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	id := c.Params("id")
 
 	res, err := a.DashboardService.GetReviewById(ctx, id)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
@@ -337,7 +716,7 @@ func (a *DashboardControllerImpl) GetReviewByID(c *fiber.Ctx) error {
 }
 
 func (a *DashboardControllerImpl) BlockAccount(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	accountId := c.Params("id")
 
 	_, err := a.DashboardService.BlockAccount(ctx, accountId)
@@ -357,17 +736,19 @@ func (a *DashboardControllerImpl) BlockAccount(c *fiber.Ctx) error {
 
 func (a *DashboardControllerImpl) GetDriverDetails(c *fiber.Ctx) error {
 	id := c.Params("id")
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	res, err := a.DashboardService.GetDriverById(ctx, id)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
 	}
 
+	setETag(c, res.Version)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "Success",
 		"data":   res,
@@ -375,19 +756,20 @@ func (a *DashboardControllerImpl) GetDriverDetails(c *fiber.Ctx) error {
 }
 
 func (a *DashboardControllerImpl) GetDrivers(c *fiber.Ctx) error {
-	var q dto.GetDriverQuery
-	ctx := c.Context()
+	ctx := c.UserContext()
 
-	if err := c.QueryParser(&q); err != nil {
+	q, errQuery := parseListQuery(c)
+	if errQuery != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"status": "error",
-			"errors": err.Error(),
+			"errors": errQuery.Error(),
 		})
 	}
 
-	res, err := a.DashboardService.GetAllDrivers(ctx, q)
+	res, page, err := a.DashboardService.GetAllDrivers(ctx, q)
+
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
@@ -396,18 +778,29 @@ func (a *DashboardControllerImpl) GetDrivers(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "Success",
 		"data": fiber.Map{
-			"drivers": res,
-			"count":   len(res),
+			"drivers":     res,
+			"count":       len(res),
+			"next_cursor": page.NextCursor,
+			"has_more":    page.HasMore,
 		},
 	})
 }
 
 func (a *DashboardControllerImpl) GetUsers(c *fiber.Ctx) error {
-	ctx := c.Context()
-	res, err := a.DashboardService.GetAllPassengers(ctx)
+	ctx := c.UserContext()
+
+	q, errQuery := parseListQuery(c)
+	if errQuery != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": errQuery.Error(),
+		})
+	}
+
+	res, page, err := a.DashboardService.GetAllPassengers(ctx, q)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
@@ -416,20 +809,22 @@ func (a *DashboardControllerImpl) GetUsers(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "Success",
 		"data": fiber.Map{
-			"users": res,
-			"count": len(res),
+			"users":       res,
+			"count":       len(res),
+			"next_cursor": page.NextCursor,
+			"has_more":    page.HasMore,
 		},
 	})
 }
 
 func (a *DashboardControllerImpl) GetUserDetails(c *fiber.Ctx) error {
 	id := c.Params("id")
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	res, err := a.DashboardService.GetPassengerById(ctx, id)
 
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(err.Code).JSON(fiber.Map{
 			"status": "error",
 			"errors": err,
 		})
@@ -441,6 +836,43 @@ func (a *DashboardControllerImpl) GetUserDetails(c *fiber.Ctx) error {
 	})
 }
 
-func NewDashboardController(service service.DashboardService) DashboardController {
-	return &DashboardControllerImpl{DashboardService: service}
+// checkRouteVersion refuses a proposal based on an outdated route right away,
+// rather than when a second admin approves it.
+func (a *DashboardControllerImpl) checkRouteVersion(ctx context.Context, id string, version uint) *helper.ErrorStruct {
+	route, err := a.DashboardService.GetRouteById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if route.Version != version {
+		return &helper.ErrorStruct{
+			Code: http.StatusPreconditionFailed,
+			Err:  helper.ErrVersionMismatch,
+		}
+	}
+
+	return nil
+}
+
+func (a *DashboardControllerImpl) checkDriverVersion(ctx context.Context, id string, version uint) *helper.ErrorStruct {
+	driver, err := a.DashboardService.GetDriverById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if driver.Version != version {
+		return &helper.ErrorStruct{
+			Code: http.StatusPreconditionFailed,
+			Err:  helper.ErrVersionMismatch,
+		}
+	}
+
+	return nil
+}
+
+func NewDashboardController(dashboardService service.DashboardService, proposalService service.ProposalService) DashboardController {
+	return &DashboardControllerImpl{
+		DashboardService: dashboardService,
+		ProposalService:  proposalService,
+	}
 }
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/gofiber/fiber/v2"
+)
+
+// setETag tags the response with the version of the entity it returns.
+func setETag(c *fiber.Ctx, version uint) {
+	c.Set(fiber.HeaderETag, `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// ifMatch reads the version a change is based on from If-Match. Without the
+// header, or with "*", version is zero and the change applies to whatever is
+// current; updates and deletes of routes and drivers, and verifying a
+// driver, refuse that with preconditionRequired. Anything but one ETag handed out by setETag can never
+// match, so ok is false for it.
+func ifMatch(c *fiber.Ctx) (version uint, ok bool) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	unquoted, found := strings.CutPrefix(header, `"`)
+	if !found {
+		return 0, false
+	}
+	unquoted, found = strings.CutSuffix(unquoted, `"`)
+	if !found {
+		return 0, false
+	}
+
+	v, err := strconv.ParseUint(unquoted, 10, 32)
+	if err != nil || v == 0 {
+		return 0, false
+	}
+
+	return uint(v), true
+}
+
+// preconditionRequired answers a change that has to name the version it is
+// based on but came without an ETag in If-Match.
+func preconditionRequired(c *fiber.Ctx) error {
+	return c.Status(http.StatusPreconditionRequired).JSON(fiber.Map{
+		"status": "error",
+		"errors": &helper.ErrorStruct{
+			Code: http.StatusPreconditionRequired,
+			Err:  helper.ErrPreconditionRequired,
+		},
+	})
+}
+
+func preconditionFailed(c *fiber.Ctx) error {
+	return c.Status(http.StatusPreconditionFailed).JSON(fiber.Map{
+		"status": "error",
+		"errors": &helper.ErrorStruct{
+			Code: http.StatusPreconditionFailed,
+			Err:  helper.ErrVersionMismatch,
+		},
+	})
+}
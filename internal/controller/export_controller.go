@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/export"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExportController interface {
+	ExportHistories(c *fiber.Ctx) error
+	ExportDrivers(c *fiber.Ctx) error
+	ExportPassengers(c *fiber.Ctx) error
+	ExportReviews(c *fiber.Ctx) error
+	ExportReport(c *fiber.Ctx) error
+	ExportMonthlyReport(c *fiber.Ctx) error
+}
+
+type ExportControllerImpl struct {
+	ExportService service.ExportService
+}
+
+func (a *ExportControllerImpl) ExportHistories(c *fiber.Ctx) error {
+	return a.exportList(c, "riwayat-perjalanan", a.ExportService.Histories)
+}
+
+func (a *ExportControllerImpl) ExportDrivers(c *fiber.Ctx) error {
+	return a.exportList(c, "pengemudi", a.ExportService.Drivers)
+}
+
+func (a *ExportControllerImpl) ExportPassengers(c *fiber.Ctx) error {
+	return a.exportList(c, "penumpang", a.ExportService.Passengers)
+}
+
+func (a *ExportControllerImpl) ExportReviews(c *fiber.Ctx) error {
+	return a.exportList(c, "ulasan", a.ExportService.Reviews)
+}
+
+func (a *ExportControllerImpl) ExportReport(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	format, ok := exportFormat(c)
+	if !ok {
+		return unknownFormat(c)
+	}
+
+	var q dto.ReportExport
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	// A CSV file holds one table.
+	if format == export.CSV && q.Table == "" {
+		q.Table = "routes"
+	}
+
+	tables, err := a.ExportService.Report(ctx, q)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return sendExport(c, "laporan", format, tables...)
+}
+
+// ExportMonthlyReport renders the whole PDF before sending it, so a failure
+// can still be answered with an error.
+func (a *ExportControllerImpl) ExportMonthlyReport(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var q dto.MonthlyReportQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	res, err := a.ExportService.MonthlyReport(ctx, q)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	var buf bytes.Buffer
+	if errPDF := export.MonthlyPDF(&buf, res); errPDF != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"status": "error",
+			"errors": errPDF.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", "laporan-bulanan-"+res.Month.Format("2006-01")+".pdf"))
+
+	return c.Send(buf.Bytes())
+}
+
+func (a *ExportControllerImpl) exportList(c *fiber.Ctx, file string, table func(ctx context.Context, q dto.ListQuery) (export.Table, *helper.ErrorStruct)) error {
+	ctx := c.UserContext()
+
+	format, ok := exportFormat(c)
+	if !ok {
+		return unknownFormat(c)
+	}
+
+	q, errQuery := parseListQuery(c)
+	if errQuery != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": errQuery.Error(),
+		})
+	}
+	delete(q.Filters, "format")
+
+	res, err := table(ctx, q)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return sendExport(c, file, format, res)
+}
+
+// exportFormat is the format query parameter, CSV by default.
+func exportFormat(c *fiber.Ctx) (string, bool) {
+	format := c.Query("format", export.CSV)
+	return format, format == export.CSV || format == export.XLSX
+}
+
+func unknownFormat(c *fiber.Ctx) error {
+	return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+		"status": "error",
+		"errors": "format must be csv or xlsx",
+	})
+}
+
+// sendExport sends tables as the response body. A CSV file is streamed: the
+// status is sent before the rows are read, so a failure halfway can only be
+// logged and leaves the file cut short. An XLSX file is built in memory
+// anyway, so it is written whole before sending and a failure is answered
+// with an error.
+func sendExport(c *fiber.Ctx, file string, format string, tables ...export.Table) error {
+	name := fmt.Sprintf("%s-%s.%s", file, time.Now().Format("20060102"), format)
+
+	var buf bytes.Buffer
+	if format == export.XLSX {
+		if err := export.Write(&buf, format, tables...); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, export.ErrTooManyRows) {
+				code = http.StatusBadRequest
+			}
+
+			return c.Status(code).JSON(fiber.Map{
+				"status": "error",
+				"errors": err.Error(),
+			})
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+
+	if format == export.XLSX {
+		return c.Send(buf.Bytes())
+	}
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.Write(w, format, tables...); err != nil {
+			log.Printf("export %s: %v", name, err)
+		}
+	})
+
+	return nil
+}
+
+func NewExportController(service service.ExportService) ExportController {
+	return &ExportControllerImpl{ExportService: service}
+}
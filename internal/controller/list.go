@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+// listParams are the query parameters ListQuery reads itself; everything
+// else on a list request is treated as a field filter.
+var listParams = map[string]bool{
+	"limit":  true,
+	"cursor": true,
+	"sort":   true,
+	"order":  true,
+}
+
+func parseListQuery(c *fiber.Ctx) (q dto.ListQuery, err error) {
+	if err := c.QueryParser(&q); err != nil {
+		return q, err
+	}
+
+	for key, value := range c.Queries() {
+		if listParams[key] || value == "" {
+			continue
+		}
+		if q.Filters == nil {
+			q.Filters = map[string]string{}
+		}
+		q.Filters[key] = value
+	}
+
+	return q, nil
+}
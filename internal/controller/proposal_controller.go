@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProposalController interface {
+	GetProposals(c *fiber.Ctx) error
+	ApproveProposal(c *fiber.Ctx) error
+	RejectProposal(c *fiber.Ctx) error
+}
+
+type ProposalControllerImpl struct {
+	ProposalService service.ProposalService
+}
+
+func (a *ProposalControllerImpl) GetProposals(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var q dto.ProposalQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err.Error(),
+		})
+	}
+
+	res, err := a.ProposalService.GetProposals(ctx, q)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data": fiber.Map{
+			"proposals": res,
+			"count":     len(res),
+		},
+	})
+}
+
+func (a *ProposalControllerImpl) ApproveProposal(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	res, err := a.ProposalService.Approve(ctx, id)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func (a *ProposalControllerImpl) RejectProposal(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id := c.Params("id")
+
+	var b dto.RejectProposal
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&b); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status": "error",
+				"errors": err,
+			})
+		}
+	}
+
+	res, err := a.ProposalService.Reject(ctx, id, b)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+// proposed is the response for a mutation that was turned into a proposal.
+func proposed(c *fiber.Ctx, res any) error {
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status":  "Success",
+		"message": "Menunggu persetujuan admin lain",
+		"data":    res,
+	})
+}
+
+func NewProposalController(service service.ProposalService) ProposalController {
+	return &ProposalControllerImpl{ProposalService: service}
+}
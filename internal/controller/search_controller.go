@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SearchController interface {
+	Search(c *fiber.Ctx) error
+}
+
+type SearchControllerImpl struct {
+	SearchService service.SearchService
+}
+
+func (a *SearchControllerImpl) Search(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	var q dto.SearchQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"errors": err.Error(),
+		})
+	}
+
+	res, err := a.SearchService.Search(ctx, q)
+
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"status": "error",
+			"errors": err,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "Success",
+		"data":   res,
+	})
+}
+
+func NewSearchController(service service.SearchService) SearchController {
+	return &SearchControllerImpl{SearchService: service}
+}
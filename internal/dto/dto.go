@@ -1,8 +1,25 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+)
+
 type (
-	GetDriverQuery struct {
-		Verified *bool `query:"verified"`
+	// ListQuery drives the list endpoints. Query parameters other than the
+	// ones below are field filters; the controller collects them in Filters.
+	ListQuery struct {
+		Limit   int               `query:"limit" validate:"min=0,max=200"`
+		Cursor  string            `query:"cursor"`
+		Sort    string            `query:"sort"`
+		Order   string            `query:"order" validate:"omitempty,oneof=asc desc"`
+		Filters map[string]string `query:"-"`
+	}
+
+	PageInfo struct {
+		NextCursor string `json:"next_cursor"`
+		HasMore    bool   `json:"has_more"`
 	}
 
 	AddRoute struct {
@@ -10,28 +27,298 @@ type (
 		Price     int    `json:"price"`
 	}
 
-	MonthReport struct {
-		Month int `query:"month"`
+	// ReportQuery selects the window of a report. Dates without a time are
+	// midnight in the report time zone, and To is exclusive. Month is the
+	// older form of asking for the last N months; without any of them the
+	// report covers the last 30 days. Compare adds the period before the
+	// window ("previous") or the same window a year earlier ("year").
+	ReportQuery struct {
+		Month       int       `query:"month" validate:"min=0"`
+		From        time.Time `query:"from"`
+		To          time.Time `query:"to"`
+		Granularity string    `query:"granularity" validate:"omitempty,oneof=hour day week month"`
+		Compare     string    `query:"compare" validate:"omitempty,oneof=previous year"`
+	}
+
+	// ReportWindow is a resolved ReportQuery. From and To are in the time
+	// zone the buckets are cut in.
+	ReportWindow struct {
+		From        time.Time
+		To          time.Time
+		Granularity string
+	}
+
+	// ReportBucket is one point of the time series of a report, covering the
+	// bucket that begins at Start.
+	ReportBucket struct {
+		Start            time.Time `json:"start"`
+		Trips            int       `json:"trips"`
+		Revenue          int64     `json:"revenue"`
+		ActiveDrivers    int       `json:"active_drivers"`
+		ActivePassengers int       `json:"active_passengers"`
 	}
 
+	// RoutesReport is what the trips of a route took in. FareRevenue is what
+	// they would have taken in at the fare in effect when each trip was made.
 	RoutesReport struct {
-		Route   string `json:"route"`
-		Total   int    `json:"total"`
-		Revenue int64  `json:"revenue"`
+		Route            string `json:"route"`
+		Total            int    `json:"total"`
+		Revenue          int64  `json:"revenue"`
+		FareRevenue      int64  `json:"fare_revenue"`
+		ActiveDrivers    int    `json:"active_drivers"`
+		ActivePassengers int    `json:"active_passengers"`
 	}
 
+	// CommonReport holds the totals of a report. TotalPassenger and
+	// TotalDriver count the accounts that exist now; everything else covers
+	// the window only.
 	CommonReport struct {
-		TotalPassenger int   `json:"total_passenger"`
-		TotalDriver    int   `json:"total_driver"`
-		TotalTrip      int   `json:"total_trip"`
-		TotalRevenue   int64 `json:"total_revenue"`
+		TotalPassenger   int   `json:"total_passenger"`
+		TotalDriver      int   `json:"total_driver"`
+		TotalTrip        int   `json:"total_trip"`
+		TotalRevenue     int64 `json:"total_revenue"`
+		ActiveDrivers    int   `json:"active_drivers"`
+		ActivePassengers int   `json:"active_passengers"`
 	}
 	Report struct {
-		Common CommonReport   `json:"common"`
-		Trips  []RoutesReport `json:"trips"`
+		From        time.Time         `json:"from"`
+		To          time.Time         `json:"to"`
+		Granularity string            `json:"granularity"`
+		Common      CommonReport      `json:"common"`
+		Trips       []RoutesReport    `json:"trips"`
+		Series      []ReportBucket    `json:"series"`
+		Comparison  *ReportComparison `json:"comparison,omitempty"`
+	}
+
+	// ReportComparison sets a report against the period From to To it was
+	// compared with.
+	ReportComparison struct {
+		Compare string        `json:"compare"`
+		From    time.Time     `json:"from"`
+		To      time.Time     `json:"to"`
+		Common  ReportChanges `json:"common"`
+		Routes  []RouteChange `json:"routes"`
+	}
+
+	ReportChanges struct {
+		Trips            Change `json:"trips"`
+		Revenue          Change `json:"revenue"`
+		ActiveDrivers    Change `json:"active_drivers"`
+		ActivePassengers Change `json:"active_passengers"`
+	}
+
+	RouteChange struct {
+		Route string `json:"route"`
+		ReportChanges
+	}
+
+	// Change is how a number moved since the earlier period. Percent is
+	// rounded to two decimals, and null when Previous is zero.
+	Change struct {
+		Previous int64    `json:"previous"`
+		Delta    int64    `json:"delta"`
+		Percent  *float64 `json:"percent"`
+	}
+
+	// ReportExport is a report to export. Table picks the routes or the
+	// time series; without it an XLSX file has both as sheets and a CSV file
+	// has the routes.
+	ReportExport struct {
+		ReportQuery
+		Table string `query:"table" validate:"omitempty,oneof=routes series"`
+	}
+
+	// MonthlyReportQuery selects the month of the printable report as
+	// YYYY-MM, the last complete month by default.
+	MonthlyReportQuery struct {
+		Month string `query:"month" validate:"omitempty,datetime=2006-01"`
+	}
+
+	// MonthlyReport is what the printable monthly report is made of: the
+	// report of Month, compared with the month before, and the drivers ranked
+	// by revenue.
+	MonthlyReport struct {
+		Month       time.Time
+		GeneratedAt time.Time
+		Report      Report
+		Drivers     []DriverEarnings
+	}
+
+	// DriverReportQuery selects the window of a driver report the same way
+	// ReportQuery does, and how the drivers are ranked. Drivers are ranked by
+	// revenue, highest first, unless Sort says otherwise.
+	DriverReportQuery struct {
+		Month int       `query:"month" validate:"min=0"`
+		From  time.Time `query:"from"`
+		To    time.Time `query:"to"`
+		Sort  string    `query:"sort" validate:"omitempty,oneof=trips revenue average_fare active_days average_rating"`
+		Order string    `query:"order" validate:"omitempty,oneof=asc desc"`
+		Limit int       `query:"limit" validate:"min=0,max=200"`
+	}
+
+	// DriverEarnings is what a driver made in the window of a report.
+	// ActiveDays counts the days, in the report time zone, the driver made a
+	// trip on, and AverageRating covers the reviews given in the window.
+	DriverEarnings struct {
+		Rank          int     `json:"rank,omitempty"`
+		DriverID      string  `json:"driver_id"`
+		Name          string  `json:"name"`
+		Trips         int     `json:"trips"`
+		Revenue       int64   `json:"revenue"`
+		AverageFare   float64 `json:"average_fare"`
+		ActiveDays    int     `json:"active_days"`
+		Reviews       int     `json:"reviews"`
+		AverageRating float64 `json:"average_rating"`
 	}
 
+	DriverReport struct {
+		From    time.Time        `json:"from"`
+		To      time.Time        `json:"to"`
+		Drivers []DriverEarnings `json:"drivers"`
+	}
+
+	// DemandQuery selects the window of a demand heatmap the same way
+	// ReportQuery does.
+	DemandQuery struct {
+		Month int       `query:"month" validate:"min=0"`
+		From  time.Time `query:"from"`
+		To    time.Time `query:"to"`
+	}
+
+	// Demand counts the trips of a window by day of the week and hour of the
+	// day in Timezone, over all trips and per route.
+	Demand struct {
+		From     time.Time     `json:"from"`
+		To       time.Time     `json:"to"`
+		Timezone string        `json:"timezone"`
+		All      DemandMatrix  `json:"all"`
+		Routes   []RouteDemand `json:"routes"`
+	}
+
+	RouteDemand struct {
+		Route string `json:"route"`
+		DemandMatrix
+	}
+
+	// DemandMatrix has a row per day of the week, Monday first, and a column
+	// per hour of the day. Peak is the busiest hour, the earliest of equals,
+	// and null without trips.
+	DemandMatrix struct {
+		Trips   [7][24]int   `json:"trips"`
+		Revenue [7][24]int64 `json:"revenue"`
+		Peak    *DemandPeak  `json:"peak"`
+	}
+
+	DemandPeak struct {
+		Weekday int `json:"weekday"`
+		Hour    int `json:"hour"`
+		Trips   int `json:"trips"`
+	}
+
+	// EditAmount is a new fare for a route. Version is set from If-Match.
 	EditAmount struct {
-		Amount int `json:"amount"`
+		Amount  int  `json:"amount"`
+		Version uint `json:"version,omitempty"`
+	}
+
+	// ScheduleFare is a fare change of a route that takes effect at
+	// EffectiveFrom, which has to be in the future.
+	ScheduleFare struct {
+		Amount        int       `json:"amount" validate:"required,min=1"`
+		EffectiveFrom time.Time `json:"effective_from" validate:"required"`
+	}
+
+	// CancelFare names the scheduled fare of a route a proposal cancels.
+	CancelFare struct {
+		FareID string `json:"fare_id"`
+	}
+
+	// Precondition is the version a proposed change was based on, taken from
+	// If-Match. Zero applies the change to whatever version is current.
+	Precondition struct {
+		Version uint `json:"version,omitempty"`
+	}
+
+	SearchQuery struct {
+		Q     string `query:"q" validate:"required,min=2,max=100"`
+		Type  string `query:"type" validate:"omitempty,oneof=driver passenger route review"`
+		Limit int    `query:"limit" validate:"min=0,max=50"`
+	}
+
+	// SearchHit is one match of the global search. Title is what the match
+	// is called, Detail a second line to tell similar titles apart.
+	SearchHit struct {
+		Type   string  `json:"type"`
+		ID     string  `json:"id"`
+		Title  string  `json:"title"`
+		Detail string  `json:"detail"`
+		Score  float64 `json:"score"`
+	}
+
+	SearchResults struct {
+		Drivers    []SearchHit `json:"drivers"`
+		Passengers []SearchHit `json:"passengers"`
+		Routes     []SearchHit `json:"routes"`
+		Reviews    []SearchHit `json:"reviews"`
+	}
+
+	// DeletionReport counts the rows an account deletion touched.
+	DeletionReport struct {
+		ID             string `json:"id"`
+		Details        int64  `json:"details"`
+		Users          int64  `json:"users"`
+		ResetPasswords int64  `json:"reset_passwords"`
+	}
+
+	PurgeReport struct {
+		Drivers    int `json:"drivers"`
+		Passengers int `json:"passengers"`
+	}
+
+	ProposalQuery struct {
+		Status string `query:"status" validate:"omitempty,oneof=pending executing approved rejected expired"`
+	}
+
+	RejectProposal struct {
+		Note string `json:"note" validate:"max=255"`
+	}
+
+	SetRole struct {
+		Role string `json:"role" validate:"required"`
+	}
+
+	CreateApiKey struct {
+		Name      string     `json:"name" validate:"required"`
+		Scopes    []string   `json:"scopes" validate:"required,min=1"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	AuditQuery struct {
+		ActorID    string    `query:"actor_id"`
+		Action     string    `query:"action"`
+		TargetType string    `query:"target_type"`
+		TargetID   string    `query:"target_id"`
+		From       time.Time `query:"from"`
+		To         time.Time `query:"to"`
+		Page       int       `query:"page" validate:"min=0"`
+		Limit      int       `query:"limit" validate:"min=0,max=200"`
+	}
+
+	// CacheStats counts the cache lookups of one instance since it started.
+	CacheStats struct {
+		Backend       string                   `json:"backend"`
+		Methods       map[string]CacheCounters `json:"methods"`
+		Invalidations map[string]int64         `json:"invalidations"`
+	}
+
+	CacheCounters struct {
+		Hits   int64 `json:"hits"`
+		Misses int64 `json:"misses"`
+		Errors int64 `json:"errors"`
+	}
+
+	ApiKeyCreated struct {
+		models.ApiKey
+		Key string `json:"key"`
 	}
 )
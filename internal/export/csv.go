@@ -0,0 +1,75 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+func writeCSV(w io.Writer, t Table) error {
+	out := csv.NewWriter(w)
+
+	header := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		header[i] = column.Header
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(t.Columns))
+	err := rows(t, func(row []any) error {
+		for i, column := range t.Columns {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = csvValue(column.Kind, row[i])
+			}
+		}
+		return out.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func csvValue(kind Kind, value any) string {
+	value = deref(value)
+	if value == nil {
+		return ""
+	}
+
+	if t, ok := value.(time.Time); ok {
+		switch {
+		case t.IsZero():
+			return ""
+		case kind == Date:
+			return t.Format("2006-01-02")
+		default:
+			return t.Format("2006-01-02 15:04:05")
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		return text(s)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// deref returns what value points to, or nil for a nil pointer.
+func deref(value any) any {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Pointer {
+		return value
+	}
+	if v.IsNil() {
+		return nil
+	}
+
+	return v.Elem().Interface()
+}
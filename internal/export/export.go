@@ -0,0 +1,112 @@
+// Package export writes tables as CSV or XLSX files for the finance team, and
+// the printable monthly report as a PDF. Rows of a table are read a page at a
+// time. A CSV file is written as the pages come in, so it never holds more
+// than one page in memory. An XLSX file is a zip archive that can only be
+// written once it is complete, so it is built in memory and holds at most
+// MaxXLSXRows rows.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats an export can be written in.
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+// MaxXLSXRows bounds the rows of all sheets of an XLSX file together.
+const MaxXLSXRows = 50000
+
+// ErrTooManyRows is returned by Write when an XLSX file would hold more than
+// MaxXLSXRows rows.
+var ErrTooManyRows = fmt.Errorf("an XLSX export holds at most %d rows, narrow it down or export CSV", MaxXLSXRows)
+
+// Kind is how the values of a column are written.
+type Kind int
+
+const (
+	// Text columns hold strings.
+	Text Kind = iota
+	// Number columns hold integers or floats.
+	Number
+	// Rupiah columns hold amounts in whole rupiah, written as numbers in CSV
+	// and with the currency format in XLSX.
+	Rupiah
+	// Time columns hold a time.Time, written in the location it is in.
+	Time
+	// Date columns hold a time.Time of which only the date is written.
+	Date
+)
+
+type Column struct {
+	Header string
+	Kind   Kind
+}
+
+// Table is one table of an export. Next returns the rows of the next page,
+// and no rows once there are none left. A nil value, or a nil pointer, leaves
+// its cell empty.
+type Table struct {
+	Name    string
+	Columns []Column
+	Next    func() ([][]any, error)
+}
+
+// ContentType is the media type of files in format.
+func ContentType(format string) string {
+	if format == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// Write writes tables to w in format. A CSV file holds a single table, an XLSX
+// file a sheet per table.
+func Write(w io.Writer, format string, tables ...Table) error {
+	switch format {
+	case CSV:
+		if len(tables) != 1 {
+			return fmt.Errorf("a CSV file holds one table, not %d", len(tables))
+		}
+		return writeCSV(w, tables[0])
+	case XLSX:
+		return writeXLSX(w, tables)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// text escapes s so a spreadsheet does not read it as a formula: a value
+// starting with one of the characters formulas can start with is prefixed
+// with a quote.
+func text(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
+
+// rows calls write for every row of t.
+func rows(t Table, write func(row []any) error) error {
+	for {
+		page, err := t.Next()
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+
+		for _, row := range page {
+			if err := write(row); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,486 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const brandName = "Mikronet"
+
+// Colors of the monthly report, as RGB.
+var (
+	brandColor = [3]int{0, 105, 92}
+	lightColor = [3]int{224, 242, 241}
+	textColor  = [3]int{33, 33, 33}
+	mutedColor = [3]int{117, 117, 117}
+)
+
+var monthNames = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// Sizes of an A4 page and its margins, in mm.
+const (
+	pageWidth    = 210.0
+	pageHeight   = 297.0
+	marginSide   = 15.0
+	marginTop    = 28.0
+	marginBottom = 20.0
+	contentWidth = pageWidth - 2*marginSide
+)
+
+// Heights of a table row and of the daily chart with its labels, in mm.
+const (
+	tableRow    = 7.0
+	chartHeight = 64.0
+)
+
+// topDrivers is how many drivers the monthly report lists.
+const topDrivers = 10
+
+type monthlyPDF struct {
+	*gofpdf.Fpdf
+	tr func(string) string
+}
+
+// MonthlyPDF writes r as the printable monthly operations report that goes to
+// the cooperative and the transport office.
+func MonthlyPDF(w io.Writer, r dto.MonthlyReport) error {
+	p := &monthlyPDF{Fpdf: gofpdf.New("P", "mm", "A4", "")}
+	p.tr = p.UnicodeTranslatorFromDescriptor("")
+
+	title := "Laporan Operasional Bulanan"
+	period := monthName(r.Month)
+
+	p.SetTitle(title+" "+period, true)
+	p.SetAuthor(brandName, true)
+	p.SetMargins(marginSide, marginTop, marginSide)
+	p.SetAutoPageBreak(true, marginBottom)
+	p.AliasNbPages("")
+
+	p.SetHeaderFunc(func() {
+		p.fill(brandColor)
+		p.Rect(0, 0, pageWidth, 18, "F")
+		p.SetXY(marginSide, 5)
+		p.SetTextColor(255, 255, 255)
+		p.SetFont("Helvetica", "B", 14)
+		p.CellFormat(contentWidth/2, 8, brandName, "", 0, "L", false, 0, "")
+		p.SetFont("Helvetica", "", 10)
+		p.CellFormat(contentWidth/2, 8, p.tr(title+" - "+period), "", 0, "R", false, 0, "")
+		p.SetY(marginTop)
+	})
+	p.SetFooterFunc(func() {
+		p.SetY(-15)
+		p.text(mutedColor)
+		p.SetFont("Helvetica", "", 8)
+		p.CellFormat(contentWidth/2, 5, "Dibuat "+r.GeneratedAt.Format("02/01/2006 15:04 MST"), "", 0, "L", false, 0, "")
+		p.CellFormat(contentWidth/2, 5, fmt.Sprintf("Halaman %d dari {nb}", p.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	p.AddPage()
+	p.text(textColor)
+	p.SetFont("Helvetica", "B", 18)
+	p.CellFormat(contentWidth, 10, p.tr(title), "", 1, "L", false, 0, "")
+	p.SetFont("Helvetica", "", 11)
+	p.CellFormat(contentWidth, 6, p.tr(fmt.Sprintf("Periode %s (%s - %s)", period, r.Report.From.Format("02/01/2006"), r.Report.To.AddDate(0, 0, -1).Format("02/01/2006"))), "", 1, "L", false, 0, "")
+	p.Ln(4)
+
+	p.summary(r)
+	p.routes(r.Report)
+	p.daily(r.Report)
+	p.drivers(r)
+	p.ratings(r.Drivers)
+
+	return p.Output(w)
+}
+
+func (p *monthlyPDF) summary(r dto.MonthlyReport) {
+	p.heading("Ringkasan", 2*tableRow)
+
+	common := r.Report.Common
+	var changes dto.ReportChanges
+	if r.Report.Comparison != nil {
+		changes = r.Report.Comparison.Common
+	}
+
+	reviews, rating := overallRating(r.Drivers)
+
+	p.table(
+		[]string{"", "Bulan ini", "Bulan lalu", "Perubahan"},
+		[]float64{60, 40, 40, 40},
+		[]string{"L", "R", "R", "R"},
+		[][]string{
+			{"Perjalanan", formatInt(int64(common.TotalTrip)), formatInt(changes.Trips.Previous), formatChange(changes.Trips, formatInt)},
+			{"Pendapatan", rupiah(common.TotalRevenue), rupiah(changes.Revenue.Previous), formatChange(changes.Revenue, rupiah)},
+			{"Pengemudi aktif", formatInt(int64(common.ActiveDrivers)), formatInt(changes.ActiveDrivers.Previous), formatChange(changes.ActiveDrivers, formatInt)},
+			{"Penumpang aktif", formatInt(int64(common.ActivePassengers)), formatInt(changes.ActivePassengers.Previous), formatChange(changes.ActivePassengers, formatInt)},
+			{"Pengemudi terdaftar", formatInt(int64(common.TotalDriver)), "-", "-"},
+			{"Penumpang terdaftar", formatInt(int64(common.TotalPassenger)), "-", "-"},
+			{"Ulasan", formatInt(int64(reviews)), "-", "-"},
+			{"Rata-rata rating", formatRating(rating, reviews), "-", "-"},
+		},
+	)
+}
+
+func (p *monthlyPDF) routes(r dto.Report) {
+	p.heading("Pendapatan per Rute", 2*tableRow)
+
+	if len(r.Trips) == 0 {
+		p.note("Tidak ada perjalanan pada rute mana pun bulan ini.")
+		return
+	}
+
+	changes := map[string]dto.Change{}
+	if r.Comparison != nil {
+		for _, route := range r.Comparison.Routes {
+			changes[route.Route] = route.Revenue
+		}
+	}
+
+	rows := make([][]string, 0, len(r.Trips))
+	labels := make([]string, 0, len(r.Trips))
+	values := make([]float64, 0, len(r.Trips))
+	for _, route := range r.Trips {
+		rows = append(rows, []string{
+			route.Route,
+			formatInt(int64(route.Total)),
+			rupiah(route.Revenue),
+			rupiah(route.FareRevenue),
+			formatInt(int64(route.ActiveDrivers)),
+			formatChange(changes[route.Route], rupiah),
+		})
+		labels = append(labels, route.Route)
+		values = append(values, float64(route.Revenue))
+	}
+
+	p.table(
+		[]string{"Rute", "Perjalanan", "Pendapatan", "Sesuai tarif", "Pengemudi", "vs bulan lalu"},
+		[]float64{30, 22, 34, 34, 22, 38},
+		[]string{"L", "R", "R", "R", "R", "R"},
+		rows,
+	)
+	p.hbars(labels, values, func(v float64) string { return rupiah(int64(v)) })
+}
+
+func (p *monthlyPDF) daily(r dto.Report) {
+	p.heading("Perjalanan Harian", chartHeight)
+
+	labels := make([]string, 0, len(r.Series))
+	values := make([]float64, 0, len(r.Series))
+	for _, bucket := range r.Series {
+		labels = append(labels, strconv.Itoa(bucket.Start.Day()))
+		values = append(values, float64(bucket.Trips))
+	}
+
+	p.vbars(labels, values)
+}
+
+func (p *monthlyPDF) drivers(r dto.MonthlyReport) {
+	p.heading(fmt.Sprintf("%d Pengemudi dengan Pendapatan Tertinggi", topDrivers), 2*tableRow)
+
+	drivers := r.Drivers
+	if len(drivers) > topDrivers {
+		drivers = drivers[:topDrivers]
+	}
+	if len(drivers) == 0 || drivers[0].Trips == 0 {
+		p.note("Tidak ada pengemudi yang melakukan perjalanan bulan ini.")
+		return
+	}
+
+	rows := make([][]string, 0, len(drivers))
+	for _, d := range drivers {
+		if d.Trips == 0 {
+			break
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(d.Rank),
+			d.Name,
+			formatInt(int64(d.Trips)),
+			rupiah(d.Revenue),
+			formatInt(int64(d.ActiveDays)),
+			formatRating(d.AverageRating, d.Reviews),
+		})
+	}
+
+	p.table(
+		[]string{"No", "Nama", "Perjalanan", "Pendapatan", "Hari aktif", "Rating"},
+		[]float64{12, 58, 24, 36, 22, 28},
+		[]string{"R", "L", "R", "R", "R", "R"},
+		rows,
+	)
+}
+
+// ratings counts the drivers by their average rating of the month, rounded to
+// whole stars, and lists the drivers rated lowest.
+func (p *monthlyPDF) ratings(drivers []dto.DriverEarnings) {
+	p.heading("Rating Pengemudi", 2*tableRow)
+
+	var rated []dto.DriverEarnings
+	for _, d := range drivers {
+		if d.Reviews > 0 {
+			rated = append(rated, d)
+		}
+	}
+	if len(rated) == 0 {
+		p.note("Tidak ada ulasan bulan ini.")
+		return
+	}
+
+	labels := []string{"1 bintang", "2 bintang", "3 bintang", "4 bintang", "5 bintang"}
+	values := make([]float64, len(labels))
+	for _, d := range rated {
+		star := int(math.Round(d.AverageRating))
+		if star >= 1 && star <= 5 {
+			values[star-1]++
+		}
+	}
+	p.note("Jumlah pengemudi menurut rata-rata rating yang diterima:")
+	p.hbars(labels, values, func(v float64) string { return formatInt(int64(v)) + " pengemudi" })
+
+	sort.SliceStable(rated, func(i, j int) bool { return rated[i].AverageRating < rated[j].AverageRating })
+	if len(rated) > 5 {
+		rated = rated[:5]
+	}
+
+	rows := make([][]string, 0, len(rated))
+	for _, d := range rated {
+		rows = append(rows, []string{d.Name, formatInt(int64(d.Trips)), formatRating(d.AverageRating, d.Reviews)})
+	}
+
+	// The list is short enough to keep on one page with its note.
+	p.space(6 + float64(len(rows)+1)*tableRow)
+	p.note("Pengemudi dengan rating terendah:")
+	p.table([]string{"Nama", "Perjalanan", "Rating"}, []float64{100, 40, 40}, []string{"L", "R", "R"}, rows)
+}
+
+// heading starts a section, on a new page unless the heading and the first
+// next mm of the section fit on this one.
+func (p *monthlyPDF) heading(s string, next float64) {
+	p.space(12 + next)
+	p.Ln(2)
+	p.text(brandColor)
+	p.SetFont("Helvetica", "B", 13)
+	p.CellFormat(contentWidth, 8, p.tr(s), "B", 1, "L", false, 0, "")
+	p.Ln(2)
+	p.text(textColor)
+}
+
+func (p *monthlyPDF) note(s string) {
+	p.text(mutedColor)
+	p.SetFont("Helvetica", "I", 9)
+	p.CellFormat(contentWidth, 6, p.tr(s), "", 1, "L", false, 0, "")
+	p.text(textColor)
+}
+
+// table draws rows under a header in the brand color, with every other row
+// shaded. A table longer than the page goes on with its header repeated.
+func (p *monthlyPDF) table(header []string, widths []float64, aligns []string, rows [][]string) {
+	const rowHeight = tableRow
+
+	drawHeader := func() {
+		p.fill(brandColor)
+		p.SetTextColor(255, 255, 255)
+		p.SetFont("Helvetica", "B", 9)
+		for i, h := range header {
+			p.CellFormat(widths[i], rowHeight, p.tr(h), "", 0, aligns[i], true, 0, "")
+		}
+		p.Ln(-1)
+		p.text(textColor)
+		p.SetFont("Helvetica", "", 9)
+	}
+
+	p.space(2 * rowHeight)
+	drawHeader()
+
+	for n, row := range rows {
+		if p.GetY()+rowHeight > pageHeight-marginBottom {
+			p.AddPage()
+			drawHeader()
+		}
+
+		p.fill(lightColor)
+		for i, cell := range row {
+			p.CellFormat(widths[i], rowHeight, p.tr(cell), "", 0, aligns[i], n%2 == 1, 0, "")
+		}
+		p.Ln(-1)
+	}
+	p.Ln(3)
+}
+
+// hbars draws a horizontal bar per label, as long as its share of the largest
+// value.
+func (p *monthlyPDF) hbars(labels []string, values []float64, format func(float64) string) {
+	const (
+		barHeight  = 5
+		rowHeight  = 7
+		labelWidth = 35
+		valueWidth = 40
+	)
+
+	top := maxOf(values)
+	p.SetFont("Helvetica", "", 8)
+
+	for i, label := range labels {
+		p.space(rowHeight)
+
+		x, y := p.GetX(), p.GetY()
+		p.CellFormat(labelWidth, rowHeight, p.tr(label), "", 0, "L", false, 0, "")
+
+		width := 0.0
+		if top > 0 {
+			width = (contentWidth - labelWidth - valueWidth) * values[i] / top
+		}
+		p.fill(brandColor)
+		if width > 0 {
+			p.Rect(x+labelWidth, y+(rowHeight-barHeight)/2, width, barHeight, "F")
+		}
+
+		p.SetX(x + labelWidth + width + 2)
+		p.CellFormat(valueWidth, rowHeight, p.tr(format(values[i])), "", 1, "L", false, 0, "")
+	}
+	p.Ln(3)
+}
+
+// vbars draws a vertical bar per label over the full width, with the largest
+// value written above the chart.
+func (p *monthlyPDF) vbars(labels []string, values []float64) {
+	p.space(chartHeight)
+
+	top := maxOf(values)
+	x, y := p.GetX(), p.GetY()
+
+	p.text(mutedColor)
+	p.SetFont("Helvetica", "", 8)
+	p.CellFormat(contentWidth, 5, "Tertinggi: "+formatInt(int64(top))+" perjalanan", "", 1, "L", false, 0, "")
+
+	slot := contentWidth / float64(len(labels))
+	base := y + chartHeight - 8
+
+	p.SetDrawColor(mutedColor[0], mutedColor[1], mutedColor[2])
+	p.Line(x, base, x+contentWidth, base)
+
+	p.fill(brandColor)
+	p.SetFont("Helvetica", "", 6)
+	for i, label := range labels {
+		if top > 0 && values[i] > 0 {
+			height := (chartHeight - 14) * values[i] / top
+			p.Rect(x+float64(i)*slot+slot*0.15, base-height, slot*0.7, height, "F")
+		}
+
+		p.SetXY(x+float64(i)*slot, base+1)
+		p.CellFormat(slot, 4, label, "", 0, "C", false, 0, "")
+	}
+
+	p.text(textColor)
+	p.SetXY(x, base+8)
+}
+
+// space starts a new page unless h mm fit on this one.
+func (p *monthlyPDF) space(h float64) {
+	if p.GetY()+h > pageHeight-marginBottom {
+		p.AddPage()
+	}
+}
+
+func (p *monthlyPDF) fill(c [3]int) {
+	p.SetFillColor(c[0], c[1], c[2])
+}
+
+func (p *monthlyPDF) text(c [3]int) {
+	p.SetTextColor(c[0], c[1], c[2])
+}
+
+// overallRating is the number of reviews of drivers and their average.
+func overallRating(drivers []dto.DriverEarnings) (reviews int, average float64) {
+	var stars float64
+	for _, d := range drivers {
+		reviews += d.Reviews
+		stars += d.AverageRating * float64(d.Reviews)
+	}
+
+	if reviews > 0 {
+		average = stars / float64(reviews)
+	}
+
+	return reviews, average
+}
+
+func maxOf(values []float64) float64 {
+	top := 0.0
+	for _, v := range values {
+		top = math.Max(top, v)
+	}
+
+	return top
+}
+
+func monthName(t time.Time) string {
+	return fmt.Sprintf("%s %d", monthNames[t.Month()-1], t.Year())
+}
+
+// formatInt writes n the Indonesian way, with dots between the thousands.
+func formatInt(n int64) string {
+	s := strconv.FormatInt(n, 10)
+
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+
+	var b strings.Builder
+	for i, digit := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+
+	return sign + b.String()
+}
+
+func rupiah(n int64) string {
+	if n < 0 {
+		return "-Rp " + formatInt(-n)
+	}
+
+	return "Rp " + formatInt(n)
+}
+
+// formatDecimal writes f with one decimal and a decimal comma.
+func formatDecimal(f float64) string {
+	return strings.Replace(strconv.FormatFloat(f, 'f', 1, 64), ".", ",", 1)
+}
+
+func formatRating(average float64, reviews int) string {
+	if reviews == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%s (%d)", formatDecimal(average), reviews)
+}
+
+// formatChange writes the delta of c with format and its percentage, if the
+// earlier period had any.
+func formatChange(c dto.Change, format func(int64) string) string {
+	delta := format(c.Delta)
+	if c.Delta > 0 {
+		delta = "+" + delta
+	}
+
+	if c.Percent == nil {
+		return delta
+	}
+
+	percent := formatDecimal(*c.Percent) + "%"
+	if *c.Percent > 0 {
+		percent = "+" + percent
+	}
+
+	return fmt.Sprintf("%s (%s)", delta, percent)
+}
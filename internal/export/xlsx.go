@@ -0,0 +1,116 @@
+package export
+
+import (
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Number formats of the XLSX columns. Excel shows the thousands separator of
+// the reader's locale, a dot in Indonesian.
+var (
+	rupiahFormat = `"Rp "#,##0`
+	timeFormat   = "yyyy-mm-dd hh:mm"
+	dateFormat   = "yyyy-mm-dd"
+)
+
+func writeXLSX(w io.Writer, tables []Table) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	header, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	styles := map[Kind]int{}
+	for kind, format := range map[Kind]*string{Rupiah: &rupiahFormat, Time: &timeFormat, Date: &dateFormat} {
+		if styles[kind], err = f.NewStyle(&excelize.Style{CustomNumFmt: format}); err != nil {
+			return err
+		}
+	}
+
+	n := 0
+	for i, t := range tables {
+		// A new file comes with one empty sheet, which becomes the first.
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), t.Name)
+		} else {
+			_, err = f.NewSheet(t.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := writeSheet(f, t, header, styles, &n); err != nil {
+			return err
+		}
+	}
+
+	return f.Write(w)
+}
+
+// writeSheet writes t to a sheet of its own and adds its rows to total.
+func writeSheet(f *excelize.File, t Table, header int, styles map[Kind]int, total *int) error {
+	sw, err := f.NewStreamWriter(t.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := sw.SetColWidth(1, len(t.Columns), 20); err != nil {
+		return err
+	}
+
+	// The header stays in view while scrolling.
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	cells := make([]any, len(t.Columns))
+	for i, column := range t.Columns {
+		cells[i] = excelize.Cell{StyleID: header, Value: column.Header}
+	}
+	if err := sw.SetRow("A1", cells); err != nil {
+		return err
+	}
+
+	n := 1
+	err = rows(t, func(row []any) error {
+		if *total++; *total > MaxXLSXRows {
+			return ErrTooManyRows
+		}
+		n++
+
+		cells := make([]any, len(t.Columns))
+		for i, column := range t.Columns {
+			if i >= len(row) {
+				break
+			}
+
+			value := deref(row[i])
+			switch v := value.(type) {
+			case time.Time:
+				if v.IsZero() {
+					value = nil
+				}
+			case string:
+				value = text(v)
+			}
+			if value != nil {
+				cells[i] = excelize.Cell{StyleID: styles[column.Kind], Value: value}
+			}
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, n)
+		if err != nil {
+			return err
+		}
+		return sw.SetRow(cell, cells)
+	})
+	if err != nil {
+		return err
+	}
+
+	return sw.Flush()
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/migrations"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// apiKeyApp registers the dashboard routes on a new app backed by a migrated
+// SQLite database, which holds the API keys.
+func apiKeyApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_NAME", filepath.Join(t.TempDir(), "api_keys.db"))
+
+	db := models.DatabaseInit()
+	m, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	dashboard := service.NewDashboardService(repository.NewMemoryDashboardRepo(repository.MemoryData{}), service.ReportConfig{})
+	DashboardHandler(app.Group("/"), db, dashboard, nil, service.ApprovalConfig{}, service.ReportConfig{}, nil)
+
+	return app, db
+}
+
+// createApiKey stores a new key with scopes and returns what a client sends.
+func createApiKey(t *testing.T, repo repository.ApiKeyRepo, id string, scopes []string, change func(k *models.ApiKey)) string {
+	raw, prefix, hash, err := helper.GenerateApiKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := models.ApiKey{ID: id, Name: id, Prefix: prefix, Hash: hash, Scopes: scopes}
+	if change != nil {
+		change(&key)
+	}
+	if _, err := repo.CreateApiKey(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func TestApiKeys(t *testing.T) {
+	app, db := apiKeyApp(t)
+	repo := repository.NewApiKeyRepo(db)
+
+	past := time.Now().Add(-time.Hour)
+	routes := createApiKey(t, repo, "routes", []string{models.PermRoutesRead}, nil)
+	drivers := createApiKey(t, repo, "drivers", []string{models.PermDriversRead}, nil)
+	revoked := createApiKey(t, repo, "revoked", []string{models.PermRoutesRead}, func(k *models.ApiKey) { k.RevokedAt = &past })
+	expired := createApiKey(t, repo, "expired", []string{models.PermRoutesRead}, func(k *models.ApiKey) { k.ExpiresAt = &past })
+
+	prefix, _ := helper.ApiKeyPrefix(routes)
+	wrongSecret := "mkd_" + prefix + "_" + "not-the-secret"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		status int
+	}{
+		{"a key with the permission", "GET", "/routes", routes, fiber.StatusOK},
+		{"another key found by its prefix", "GET", "/drivers", drivers, fiber.StatusOK},
+		{"a key without the permission", "GET", "/drivers", routes, fiber.StatusForbidden},
+		{"a known prefix with the wrong secret", "GET", "/routes", wrongSecret, fiber.StatusUnauthorized},
+		{"an unknown prefix", "GET", "/routes", "mkd_00000000_secret", fiber.StatusUnauthorized},
+		{"a malformed key", "GET", "/routes", "secret", fiber.StatusUnauthorized},
+		{"a revoked key", "GET", "/routes", revoked, fiber.StatusUnauthorized},
+		{"an expired key", "GET", "/routes", expired, fiber.StatusUnauthorized},
+		{"a route that only needs an admin", "POST", "/auth/logout", routes, fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("X-API-Key", tt.key)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: %s %s answered %d, want %d", tt.name, tt.method, tt.path, resp.StatusCode, tt.status)
+		}
+	}
+
+	used, err := repo.GetApiKeyByPrefix(context.Background(), prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used.ID != "routes" || used.LastUsedAt == nil {
+		t.Errorf("prefix %s: got key %s last used at %v, want routes with a time", prefix, used.ID, used.LastUsedAt)
+	}
+	if _, err := repo.GetApiKeyByPrefix(context.Background(), "00000000"); !errors.Is(err, helper.ErrNotFound) {
+		t.Errorf("unknown prefix: got %v, want %v", err, helper.ErrNotFound)
+	}
+}
@@ -9,36 +9,102 @@ import (
 	"gorm.io/gorm"
 )
 
-func DashboardHandler(r fiber.Router, db *gorm.DB) {
-	repo := repository.NewDashboardRepo(db)
-	serviceDashboard := service.NewDashboardService(repo)
-	controllerDashboard := controller.NewDashboardController(serviceDashboard)
+// DashboardHandler registers the dashboard routes. serviceDashboard is shared
+// with the jobs main starts, and dashboardCache is nil unless it sits in front
+// of serviceDashboard.
+func DashboardHandler(r fiber.Router, db *gorm.DB, serviceDashboard service.DashboardService, verifier *middleware.Verifier, approvals service.ApprovalConfig, reports service.ReportConfig, dashboardCache *service.DashboardCache) {
+	controllerCache := controller.NewCacheController(dashboardCache)
 
-	api := r.Group("/")
+	proposalRepo := repository.NewProposalRepo(db)
+	serviceProposal := service.NewProposalService(proposalRepo, serviceDashboard, approvals)
+	controllerProposal := controller.NewProposalController(serviceProposal)
+
+	controllerDashboard := controller.NewDashboardController(serviceDashboard, serviceProposal)
+
+	serviceExport := service.NewExportService(serviceDashboard, reports)
+	controllerExport := controller.NewExportController(serviceExport)
+
+	adminRepo := repository.NewAdminRepo(db)
+	revocationRepo := repository.NewRevocationRepo(db)
+	serviceAdmin := service.NewAdminService(adminRepo, revocationRepo)
+	controllerAdmin := controller.NewAdminController(serviceAdmin)
+
+	apiKeyRepo := repository.NewApiKeyRepo(db)
+	serviceApiKey := service.NewApiKeyService(apiKeyRepo)
+	controllerApiKey := controller.NewApiKeyController(serviceApiKey)
+
+	auditRepo := repository.NewAuditRepo(db)
+	serviceAudit := service.NewAuditService(auditRepo)
+	controllerAudit := controller.NewAuditController(serviceAudit)
+
+	searchRepo := repository.NewSearchRepo(db)
+	serviceSearch := service.NewSearchService(searchRepo)
+	controllerSearch := controller.NewSearchController(serviceSearch)
+
+	auth := middleware.NewAuthorizer(adminRepo, revocationRepo, apiKeyRepo, verifier)
+
+	api := r.Group("/", auth.Enforce(DashboardPolicies))
 	api.Get("/ktp/:id", controllerDashboard.GetKTP)
 
 	api.Get("/users", controllerDashboard.GetUsers)
 	api.Get("/users/:id", controllerDashboard.GetUserDetails)
-	api.Delete("/users/:id", middleware.ValidateDashboardRole, controllerDashboard.DeleteUser)
+	api.Delete("/users/:id", controllerDashboard.DeleteUser)
+	api.Post("/users/:id/restore", controllerDashboard.RestoreUser)
 
 	api.Get("/drivers", controllerDashboard.GetDrivers)
 	api.Get("/drivers/:id", controllerDashboard.GetDriverDetails)
-	api.Post("/drivers/verified/:id", middleware.ValidateDashboardRole, controllerDashboard.SetDriverStatusVerified)
-	api.Delete("/drivers/:id", middleware.ValidateDashboardRole, controllerDashboard.DeleteDriver)
+	api.Post("/drivers/verified/:id", controllerDashboard.SetDriverStatusVerified)
+	api.Delete("/drivers/:id", controllerDashboard.DeleteDriver)
+	api.Post("/drivers/:id/restore", controllerDashboard.RestoreDriver)
+	api.Get("/drivers/:id/earnings", controllerDashboard.GetDriverEarnings)
 
-	api.Get("/block", middleware.ValidateDashboardRole, controllerDashboard.GetAllBlockAccount)
-	api.Post("/block/:id", middleware.ValidateDashboardRole, controllerDashboard.BlockAccount)
-	api.Put("/block/:id", middleware.ValidateDashboardRole, controllerDashboard.UnblockAccount)
+	api.Get("/block", controllerDashboard.GetAllBlockAccount)
+	api.Post("/block/:id", controllerDashboard.BlockAccount)
+	api.Put("/block/:id", controllerDashboard.UnblockAccount)
 
 	api.Get("/reviews", controllerDashboard.GetReviews)
 	api.Get("/reviews/:id", controllerDashboard.GetReviewByID)
 
 	api.Get("/routes", controllerDashboard.GetRoutes)
-	api.Post("/route", middleware.ValidateDashboardRole, controllerDashboard.AddRoute)
-	api.Put("/route/:id", middleware.ValidateDashboardRole, controllerDashboard.EditAmountRoute)
-	api.Delete("/route/:id", middleware.ValidateDashboardRole, controllerDashboard.DeleteRoute)
+	api.Post("/route", controllerDashboard.AddRoute)
+	api.Get("/route/:id", controllerDashboard.GetRouteByID)
+	api.Put("/route/:id", controllerDashboard.EditAmountRoute)
+	api.Delete("/route/:id", controllerDashboard.DeleteRoute)
+	api.Get("/route/:id/fares", controllerDashboard.GetRouteFares)
+	api.Post("/route/:id/fares", controllerDashboard.ScheduleRouteFare)
+	api.Delete("/route/:id/fares/:fareId", controllerDashboard.CancelRouteFare)
 
 	api.Get("/histories", controllerDashboard.GetAllTripHistories)
 
-	api.Get("/reports", controllerDashboard.MonthlyReport)
+	api.Get("/reports", controllerDashboard.GetReport)
+	api.Get("/reports/drivers", controllerDashboard.GetDriverReport)
+	api.Get("/reports/demand", controllerDashboard.GetDemand)
+	api.Get("/reports/monthly.pdf", controllerExport.ExportMonthlyReport)
+
+	api.Get("/export/histories", controllerExport.ExportHistories)
+	api.Get("/export/drivers", controllerExport.ExportDrivers)
+	api.Get("/export/passengers", controllerExport.ExportPassengers)
+	api.Get("/export/reviews", controllerExport.ExportReviews)
+	api.Get("/export/report", controllerExport.ExportReport)
+
+	api.Get("/search", controllerSearch.Search)
+
+	api.Get("/cache/stats", controllerCache.GetCacheStats)
+
+	api.Get("/roles", controllerAdmin.GetRoles)
+	api.Put("/admins/:id/role", controllerAdmin.SetAdminRole)
+	api.Post("/admins/:id/revoke-sessions", controllerAdmin.RevokeSessions)
+
+	api.Post("/auth/logout", controllerAdmin.Logout)
+
+	api.Get("/audit", controllerAudit.GetAuditLogs)
+
+	api.Get("/proposals", controllerProposal.GetProposals)
+	api.Post("/proposals/:id/approve", controllerProposal.ApproveProposal)
+	api.Post("/proposals/:id/reject", controllerProposal.RejectProposal)
+
+	api.Get("/api-keys", controllerApiKey.GetApiKeys)
+	api.Post("/api-keys", controllerApiKey.CreateApiKey)
+	api.Delete("/api-keys/:id", controllerApiKey.RevokeApiKey)
+	api.Post("/api-keys/:id/rotate", controllerApiKey.RotateApiKey)
 }
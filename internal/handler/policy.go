@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/middleware"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+)
+
+// DashboardPolicies lists the access policy of every dashboard route. A route
+// missing from this table is refused at request time, fails
+// TestEveryRouteHasAPolicy and makes startup fail.
+var DashboardPolicies = middleware.Policies{
+	"GET /ktp/:id": middleware.Permission(models.PermDriversKTP),
+
+	"GET /users":        middleware.Permission(models.PermUsersRead),
+	"GET /users/:id":    middleware.Permission(models.PermUsersRead),
+	"DELETE /users/:id": middleware.Permission(models.PermUsersDelete),
+
+	"POST /users/:id/restore": middleware.Permission(models.PermUsersDelete),
+
+	"GET /drivers":               middleware.Permission(models.PermDriversRead),
+	"GET /drivers/:id":           middleware.Permission(models.PermDriversRead),
+	"POST /drivers/verified/:id": middleware.Permission(models.PermDriversVerify),
+	"DELETE /drivers/:id":        middleware.Permission(models.PermDriversDelete),
+	"POST /drivers/:id/restore":  middleware.Permission(models.PermDriversDelete),
+
+	"GET /drivers/:id/earnings": middleware.Permission(models.PermReportsRead),
+
+	"GET /block":      middleware.Permission(models.PermAccountsRead),
+	"POST /block/:id": middleware.Permission(models.PermAccountsBlock),
+	"PUT /block/:id":  middleware.Permission(models.PermAccountsBlock),
+
+	"GET /reviews":     middleware.Permission(models.PermReviewsRead),
+	"GET /reviews/:id": middleware.Permission(models.PermReviewsRead),
+
+	"GET /routes":       middleware.Permission(models.PermRoutesRead),
+	"POST /route":       middleware.Permission(models.PermRoutesWrite),
+	"GET /route/:id":    middleware.Permission(models.PermRoutesRead),
+	"PUT /route/:id":    middleware.Permission(models.PermRoutesWrite),
+	"DELETE /route/:id": middleware.Permission(models.PermRoutesWrite),
+
+	"GET /route/:id/fares":            middleware.Permission(models.PermRoutesRead),
+	"POST /route/:id/fares":           middleware.Permission(models.PermRoutesWrite),
+	"DELETE /route/:id/fares/:fareId": middleware.Permission(models.PermRoutesWrite),
+
+	"GET /histories": middleware.Permission(models.PermReportsRead),
+
+	"GET /reports":         middleware.Permission(models.PermReportsRead),
+	"GET /reports/drivers": middleware.Permission(models.PermReportsRead),
+	"GET /reports/demand":  middleware.Permission(models.PermReportsRead),
+
+	"GET /reports/monthly.pdf": middleware.Permission(models.PermReportsRead),
+
+	"GET /export/histories":  middleware.Permission(models.PermReportsRead),
+	"GET /export/drivers":    middleware.Permission(models.PermDriversRead),
+	"GET /export/passengers": middleware.Permission(models.PermUsersRead),
+	"GET /export/reviews":    middleware.Permission(models.PermReviewsRead),
+	"GET /export/report":     middleware.Permission(models.PermReportsRead),
+
+	"GET /search": middleware.Permission(models.PermSearch),
+
+	"GET /cache/stats": middleware.Permission(models.PermAdminsWrite),
+
+	"GET /roles":           middleware.Permission(models.PermAdminsWrite),
+	"PUT /admins/:id/role": middleware.Permission(models.PermAdminsWrite),
+
+	"POST /admins/:id/revoke-sessions": middleware.Permission(models.PermAdminsWrite),
+
+	"POST /auth/logout": middleware.Authenticated(),
+
+	"GET /audit": middleware.Permission(models.PermAuditRead),
+
+	"GET /proposals":              middleware.Permission(models.PermProposalsDecide),
+	"POST /proposals/:id/approve": middleware.Permission(models.PermProposalsDecide),
+	"POST /proposals/:id/reject":  middleware.Permission(models.PermProposalsDecide),
+
+	"GET /api-keys":             middleware.Permission(models.PermApiKeysWrite),
+	"POST /api-keys":            middleware.Permission(models.PermApiKeysWrite),
+	"DELETE /api-keys/:id":      middleware.Permission(models.PermApiKeysWrite),
+	"POST /api-keys/:id/rotate": middleware.Permission(models.PermApiKeysWrite),
+}
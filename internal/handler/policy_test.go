@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// dashboardApp registers the dashboard routes on a new app. Nothing is
+// called while routes are registered, so the dashboard data is an empty
+// in-memory repository and there is no database.
+func dashboardApp() *fiber.App {
+	app := fiber.New()
+
+	dashboard := service.NewDashboardService(repository.NewMemoryDashboardRepo(repository.MemoryData{}), service.ReportConfig{})
+	DashboardHandler(app.Group("/"), nil, dashboard, nil, service.ApprovalConfig{}, service.ReportConfig{}, nil)
+
+	return app
+}
+
+func TestEveryRouteHasAPolicy(t *testing.T) {
+	app := dashboardApp()
+
+	if err := DashboardPolicies.Verify(app.GetRoutes(true)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyReportsRoutesWithoutPolicy(t *testing.T) {
+	app := dashboardApp()
+	app.Get("/unguarded", func(c *fiber.Ctx) error { return nil })
+
+	err := DashboardPolicies.Verify(app.GetRoutes(true))
+	if err == nil {
+		t.Fatal("got no error for a route without a policy")
+	}
+	if !strings.Contains(err.Error(), "GET /unguarded") {
+		t.Fatalf("got %q, want it to name GET /unguarded", err)
+	}
+}
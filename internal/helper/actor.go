@@ -0,0 +1,33 @@
+package helper
+
+import (
+	"context"
+	"slices"
+)
+
+type actorKey struct{}
+
+// Actor identifies who triggered a request: an admin or an API key.
+// Permissions are those of the admin's role or the scopes of the key, and
+// are only loaded on routes that need a permission.
+type Actor struct {
+	Type        string
+	ID          string
+	IP          string
+	Permissions []string
+}
+
+func (a Actor) Can(permission string) bool {
+	return slices.Contains(a.Permissions, permission)
+}
+
+func WithActor(c context.Context, actor Actor) context.Context {
+	return context.WithValue(c, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor stored by the auth middleware, or the zero
+// Actor for work that was not started by a request.
+func ActorFrom(c context.Context) Actor {
+	actor, _ := c.Value(actorKey{}).(Actor)
+	return actor
+}
@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+const apiKeyTag = "mkd"
+
+// GenerateApiKey returns a new key of the form mkd_<prefix>_<secret>. Only
+// the prefix and the hash are meant to be stored; the key itself is shown
+// to the caller once.
+func GenerateApiKey() (key string, prefix string, hash string, err error) {
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", err
+	}
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyTag + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	return key, prefix, HashApiKey(key), nil
+}
+
+// ApiKeyPrefix extracts the lookup prefix of a key presented by a client.
+func ApiKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyTag || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
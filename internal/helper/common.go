@@ -6,14 +6,23 @@ import (
 )
 
 var (
-	ErrNotFound          = fmt.Errorf("data not found")
-	ErrDuplicateEntry    = fmt.Errorf("duplicate entry on email")
-	ErrInvalidInput      = fmt.Errorf("invalid input")
-	ErrUnauthorized      = fmt.Errorf("unauthorized")
-	ErrDatabase          = fmt.Errorf("database error")
-	ErrInternal          = fmt.Errorf("internal server error")
-	ErrBadRequest        = fmt.Errorf("bad request")
-	ErrPasswordIncorrect = fmt.Errorf("password incorrect")
+	ErrNotFound             = fmt.Errorf("data not found")
+	ErrDuplicateEntry       = fmt.Errorf("duplicate entry on email")
+	ErrInvalidInput         = fmt.Errorf("invalid input")
+	ErrUnauthorized         = fmt.Errorf("unauthorized")
+	ErrDatabase             = fmt.Errorf("database error")
+	ErrInternal             = fmt.Errorf("internal server error")
+	ErrBadRequest           = fmt.Errorf("bad request")
+	ErrPasswordIncorrect    = fmt.Errorf("password incorrect")
+	ErrSelfApproval         = fmt.Errorf("requester cannot approve own proposal")
+	ErrActionPermission     = fmt.Errorf("approving needs the permission of the proposed action")
+	ErrProposalClosed       = fmt.Errorf("proposal is no longer pending")
+	ErrVersionMismatch      = fmt.Errorf("data was changed since it was read")
+	ErrPreconditionRequired = fmt.Errorf("the ETag the change is based on is required in If-Match")
+	ErrFareExists           = fmt.Errorf("a fare already starts at that time")
+	ErrFareInEffect         = fmt.Errorf("fare is already in effect")
+	ErrInUse                = fmt.Errorf("data is still referenced by other records")
+	ErrReportWindow         = fmt.Errorf("report window is empty or has too many buckets")
 )
 
 type ErrorStruct struct {
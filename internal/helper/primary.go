@@ -0,0 +1,29 @@
+package helper
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type writesKey struct{}
+
+// WithWriteTracking returns a context that remembers whether a database write
+// was made with it, so later reads in the same request can avoid a replica
+// that has not caught up yet.
+func WithWriteTracking(c context.Context) context.Context {
+	return context.WithValue(c, writesKey{}, new(atomic.Bool))
+}
+
+// MarkWritten records a write in c. Calling it by hand forces the remaining
+// reads of the request to the primary.
+func MarkWritten(c context.Context) {
+	if written, ok := c.Value(writesKey{}).(*atomic.Bool); ok {
+		written.Store(true)
+	}
+}
+
+// HasWritten reports whether MarkWritten was called on c.
+func HasWritten(c context.Context) bool {
+	written, ok := c.Value(writesKey{}).(*atomic.Bool)
+	return ok && written.Load()
+}
@@ -0,0 +1,69 @@
+package helper
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ParseTime accepts either a plain date, read as midnight local time, or a
+// full RFC 3339 timestamp.
+func ParseTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// RegisterQueryTypes teaches fiber's query parser to fill time.Time fields.
+func RegisterQueryTypes() {
+	fiber.SetParserDecoder(fiber.ParserConfig{
+		IgnoreUnknownKeys: true,
+		ZeroEmpty:         true,
+		ParserType: []fiber.ParserType{{
+			Customtype: time.Time{},
+			Converter: func(value string) reflect.Value {
+				t, err := ParseTime(value)
+				if err != nil {
+					return reflect.Value{}
+				}
+				return reflect.ValueOf(t)
+			},
+		}},
+	})
+}
+
+// BucketStart returns the start of the hour, day, week (from Monday) or month
+// that t falls in, in the location of t.
+func BucketStart(t time.Time, granularity string) time.Time {
+	y, m, d := t.Date()
+
+	switch granularity {
+	case "hour":
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case "week":
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// NextBucket returns the start of the bucket after the one starting at start.
+func NextBucket(start time.Time, granularity string) time.Time {
+	y, m, d := start.Date()
+
+	switch granularity {
+	case "hour":
+		return time.Date(y, m, d, start.Hour()+1, 0, 0, 0, start.Location())
+	case "week":
+		return time.Date(y, m, d+7, 0, 0, 0, 0, start.Location())
+	case "month":
+		return time.Date(y, m+1, 1, 0, 0, 0, 0, start.Location())
+	default:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, start.Location())
+	}
+}
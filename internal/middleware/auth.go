@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+const apiKeyKey = "api_key"
+
+// apiKeyTouchInterval throttles last_used_at writes for busy keys.
+const apiKeyTouchInterval = time.Minute
+
+type Authorizer struct {
+	AdminRepo      repository.AdminRepo
+	RevocationRepo repository.RevocationRepo
+	ApiKeyRepo     repository.ApiKeyRepo
+	Verifier       *Verifier
+}
+
+// ApiKeyFrom returns the API key that authenticated the request, or nil when
+// the caller is an admin or the route is public.
+func ApiKeyFrom(c *fiber.Ctx) *models.ApiKey {
+	key, _ := c.Locals(apiKeyKey).(*models.ApiKey)
+	return key
+}
+
+// Enforce applies policies to every route of the group it is mounted on.
+// Requests that match no policy are refused. Callers authenticate either
+// with an admin bearer token or with an X-API-Key header.
+func (a *Authorizer) Enforce(policies Policies) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		policy, _ := policies.Lookup(c.Method(), c.Path())
+
+		switch policy.Access {
+		case AccessPublic:
+			return c.Next()
+		case AccessDenied:
+			return forbidden(c)
+		}
+
+		if key := c.Get("X-API-Key"); key != "" {
+			return a.enforceApiKey(c, policy, key)
+		}
+
+		return a.enforceAdmin(c, policy)
+	}
+}
+
+func (a *Authorizer) enforceAdmin(c *fiber.Ctx, policy Policy) error {
+	claims, err := a.Verifier.Verify(c.UserContext(), c.Get("Authorization"))
+
+	if err != nil {
+		return unauthorized(c)
+	}
+
+	if claims.Role != "admin" || claims.AdminID() == "" {
+		return forbidden(c)
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, errRevoked := a.RevocationRepo.IsRevoked(c.Context(), claims.ID, claims.AdminID(), issuedAt)
+
+	if errRevoked != nil {
+		return internalError(c)
+	}
+
+	if revoked {
+		return unauthorized(c)
+	}
+
+	c.Locals(claimsKey, claims)
+
+	if policy.Access == AccessAuthenticated {
+		setActor(c, "admin", claims.AdminID(), nil)
+		return c.Next()
+	}
+
+	permissions, errRepo := a.AdminRepo.GetPermissions(c.Context(), claims.AdminID())
+
+	if errRepo != nil {
+		return internalError(c)
+	}
+
+	setActor(c, "admin", claims.AdminID(), permissions)
+
+	if slices.Contains(permissions, policy.Permission) {
+		return c.Next()
+	}
+
+	return forbidden(c)
+}
+
+// enforceApiKey only admits keys whose scopes include the route permission;
+// routes that merely need an authenticated admin are closed to API keys.
+func (a *Authorizer) enforceApiKey(c *fiber.Ctx, policy Policy, raw string) error {
+	prefix, ok := helper.ApiKeyPrefix(raw)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	key, err := a.ApiKeyRepo.GetApiKeyByPrefix(c.Context(), prefix)
+
+	if err != nil {
+		if errors.Is(err, helper.ErrNotFound) {
+			return unauthorized(c)
+		}
+		return internalError(c)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(helper.HashApiKey(raw))) != 1 {
+		return unauthorized(c)
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return unauthorized(c)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := a.ApiKeyRepo.TouchApiKey(c.Context(), key.ID, now); err != nil {
+			log.Printf("record api key usage: %v", err)
+		}
+	}
+
+	c.Locals(apiKeyKey, &key)
+	setActor(c, "api_key", key.ID, key.Scopes)
+
+	if policy.Access == AccessPermission && key.Scopes.Has(policy.Permission) {
+		return c.Next()
+	}
+
+	return forbidden(c)
+}
+
+// setActor exposes the caller to the lower layers through the user context,
+// which is what controllers hand to the services.
+func setActor(c *fiber.Ctx, actorType string, id string, permissions []string) {
+	c.SetUserContext(helper.WithActor(c.UserContext(), helper.Actor{
+		Type:        actorType,
+		ID:          id,
+		IP:          clientIP(c),
+		Permissions: permissions,
+	}))
+}
+
+// clientIP prefers the last X-Forwarded-For entry, which is the address the
+// gateway in front of this service saw; earlier entries are client supplied.
+func clientIP(c *fiber.Ctx) string {
+	if ips := c.IPs(); len(ips) > 0 {
+		return ips[len(ips)-1]
+	}
+
+	return c.IP()
+}
+
+func unauthorized(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"status":  "error",
+		"message": "Unauthorized",
+	})
+}
+
+func forbidden(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"status":  "error",
+		"message": "Forbidden access",
+	})
+}
+
+func internalError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"status":  "error",
+		"message": "Internal server error",
+	})
+}
+
+func NewAuthorizer(adminRepo repository.AdminRepo, revocationRepo repository.RevocationRepo, apiKeyRepo repository.ApiKeyRepo, verifier *Verifier) *Authorizer {
+	return &Authorizer{
+		AdminRepo:      adminRepo,
+		RevocationRepo: revocationRepo,
+		ApiKeyRepo:     apiKeyRepo,
+		Verifier:       verifier,
+	}
+}
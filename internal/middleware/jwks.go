@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeySet holds the public keys of a JWKS document, indexed by kid. The
+// document is read from a local file or fetched from an http(s) URL.
+type KeySet struct {
+	source string
+	client *http.Client
+
+	mu         sync.RWMutex
+	keys       map[string]any
+	lastReload time.Time
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// errUnsupportedKey is returned for a key of a type or curve the service
+// cannot verify tokens with.
+var errUnsupportedKey = errors.New("unsupported key")
+
+func NewKeySet(source string) *KeySet {
+	return &KeySet{
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   map[string]any{},
+	}
+}
+
+// Load replaces the current keys with the ones in the JWKS document. Keys of a
+// type or curve that is not supported are skipped, so an issuer can publish
+// them next to the keys the service uses. On failure the previous keys stay
+// in use.
+func (k *KeySet) Load(ctx context.Context) error {
+	raw, err := k.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("parse jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(doc.Keys))
+	for _, key := range doc.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		pub, err := key.publicKey()
+		if errors.Is(err, errUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("parse jwk %q: %w", key.Kid, err)
+		}
+		keys[key.Kid] = pub
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("jwks %s has no supported signing keys", k.source)
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.lastReload = time.Now()
+	k.mu.Unlock()
+
+	return nil
+}
+
+// Refresh reloads the document every interval until ctx is done, so rotated
+// keys are picked up without a restart.
+func (k *KeySet) Refresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Load(ctx); err != nil {
+				log.Printf("jwks reload failed: %v", err)
+			}
+		}
+	}
+}
+
+// Key returns the key for kid. An unknown kid triggers one early reload per
+// minute, since it usually means the issuer has just rotated its keys. An
+// empty kid is accepted only while the set holds a single key.
+func (k *KeySet) Key(ctx context.Context, kid string) (any, error) {
+	if key, ok := k.lookup(kid); ok {
+		return key, nil
+	}
+
+	k.mu.Lock()
+	stale := time.Since(k.lastReload) > time.Minute
+	if stale {
+		k.lastReload = time.Now()
+	}
+	k.mu.Unlock()
+
+	if stale {
+		if err := k.Load(ctx); err != nil {
+			log.Printf("jwks reload failed: %v", err)
+		}
+
+		if key, ok := k.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (k *KeySet) lookup(kid string) (any, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+func (k *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(k.source, "http://") && !strings.HasPrefix(k.source, "https://") {
+		return os.ReadFile(k.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func (j jwk) publicKey() (any, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: curve %q", errUnsupportedKey, j.Crv)
+		}
+
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("%w: key type %q", errUnsupportedKey, j.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
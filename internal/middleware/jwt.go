@@ -1,54 +1,134 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GetJWTPayload(tokenString, secretKey string) (jwt.MapClaims, error) {
-	if tokenString == "" {
+const claimsKey = "claims"
+
+type Claims struct {
+	jwt.RegisteredClaims
+	UserID string `json:"id"`
+	Role   string `json:"role"`
+}
+
+// AdminID is the id of the acting admin. Tokens from the auth service carry
+// it in "id", standard issuers in "sub".
+func (c *Claims) AdminID() string {
+	if c.UserID != "" {
+		return c.UserID
+	}
+
+	return c.Subject
+}
+
+// ClaimsFrom returns the claims of the authenticated request, or nil on
+// public routes.
+func ClaimsFrom(c *fiber.Ctx) *Claims {
+	claims, _ := c.Locals(claimsKey).(*Claims)
+	return claims
+}
+
+// Verifier checks bearer tokens signed either with the shared HMAC secret or
+// with one of the asymmetric keys of a JWKS document.
+type Verifier struct {
+	secret []byte
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+func (v *Verifier) Verify(ctx context.Context, header string) (*Claims, error) {
+	scheme, tokenString, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || tokenString == "" {
 		return nil, fmt.Errorf("token is empty")
 	}
 
-	token, err := jwt.Parse(tokenString[7:], func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+	claims := &Claims{}
+	_, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if v.secret == nil {
+				return nil, fmt.Errorf("hmac tokens are not accepted")
+			}
+			return v.secret, nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+			if v.keys == nil {
+				return nil, fmt.Errorf("asymmetric tokens are not accepted")
+			}
+			kid, _ := token.Header["kid"].(string)
+			return v.keys.Key(ctx, kid)
+		default:
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secretKey), nil
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
-	} else {
-		return nil, fmt.Errorf("invalid JWT claims")
-	}
+	return claims, nil
 }
 
-func ValidateDashboardRole(c *fiber.Ctx) error {
-	token := c.Get("Authorization")
-	payload, err := GetJWTPayload(token, os.Getenv("JWT_SECRET"))
+// NewVerifierFromEnv builds a Verifier from JWT_SECRET (HS256), JWT_JWKS (a
+// JWKS file path or URL for RS256/ES256), JWT_JWKS_REFRESH, JWT_ISSUER and
+// JWT_AUDIENCE. At least one of JWT_SECRET and JWT_JWKS must be set.
+func NewVerifierFromEnv(ctx context.Context) (*Verifier, error) {
+	v := &Verifier{}
+	var methods []string
 
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Unauthorized",
-		})
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		v.secret = []byte(secret)
+		methods = append(methods, "HS256", "HS384", "HS512")
 	}
 
-	if payload["role"] == "admin" {
-		return c.Next()
+	if source := os.Getenv("JWT_JWKS"); source != "" {
+		v.keys = NewKeySet(source)
+		if err := v.keys.Load(ctx); err != nil {
+			return nil, err
+		}
+
+		interval := 10 * time.Minute
+		if raw := os.Getenv("JWT_JWKS_REFRESH"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid JWT_JWKS_REFRESH %q", raw)
+			}
+			interval = d
+		}
+
+		go v.keys.Refresh(ctx, interval)
+		methods = append(methods, "RS256", "RS384", "RS512", "ES256", "ES384", "ES512")
 	}
 
-	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-		"status":  "error",
-		"message": "Forbidden access",
-	})
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("neither JWT_SECRET nor JWT_JWKS is set")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	} else {
+		log.Print("JWT_AUDIENCE is not set, token audience is not checked")
+	}
+
+	v.parser = jwt.NewParser(opts...)
 
+	return v, nil
 }
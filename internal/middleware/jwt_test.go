@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "secret"
+
+var (
+	rsaKeyOnce sync.Once
+	rsaKey     *rsa.PrivateKey
+)
+
+// testRSAKey returns an RSA key shared by the tests, since generating one is
+// slow.
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	rsaKeyOnce.Do(func() {
+		var err error
+		if rsaKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	return rsaKey
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jwk {
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) jwk {
+	return jwk{
+		Kty: "EC",
+		Kid: kid,
+		Crv: key.Curve.Params().Name,
+		X:   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}
+}
+
+// jwksServer serves the keys it holds as a JWKS document and counts the
+// requests for it.
+type jwksServer struct {
+	*httptest.Server
+	hits atomic.Int32
+
+	mu   sync.Mutex
+	keys []jwk
+}
+
+func newJWKSServer(t *testing.T, keys ...jwk) *jwksServer {
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...jwk) {
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+// newTestVerifier builds a Verifier from the environment the way main does,
+// with the JWKS served by s when it is not nil.
+func newTestVerifier(t *testing.T, s *jwksServer) *Verifier {
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("JWT_ISSUER", "auth")
+	t.Setenv("JWT_AUDIENCE", "dashboard")
+	t.Setenv("JWT_JWKS_REFRESH", "1h")
+	if s != nil {
+		t.Setenv("JWT_JWKS", s.URL)
+	} else {
+		t.Setenv("JWT_JWKS", "")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	v, err := NewVerifierFromEnv(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"id":  "adm",
+		"iss": "auth",
+		"aud": "dashboard",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims, key any) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return "Bearer " + s
+}
+
+func TestVerify(t *testing.T) {
+	key := testRSAKey(t)
+	v := newTestVerifier(t, newJWKSServer(t, rsaJWK("k1", &key.PublicKey)))
+
+	publicPEM, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicPEM})
+
+	with := func(name string, value any) jwt.MapClaims {
+		claims := validClaims()
+		claims[name] = value
+		return claims
+	}
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		ok     bool
+	}{
+		{"HS256 with the secret", sign(t, jwt.SigningMethodHS256, "", validClaims(), []byte(testSecret)), true},
+		{"RS256 with a key of the set", sign(t, jwt.SigningMethodRS256, "k1", validClaims(), key), true},
+		{"HS256 signed with the public key of the set", sign(t, jwt.SigningMethodHS256, "k1", validClaims(), publicPEM), false},
+		{"alg none", "Bearer " + none, false},
+		{"expired a minute ago", sign(t, jwt.SigningMethodHS256, "", with("exp", time.Now().Add(-time.Minute).Unix()), []byte(testSecret)), false},
+		{"expired inside the leeway", sign(t, jwt.SigningMethodHS256, "", with("exp", time.Now().Add(-10*time.Second).Unix()), []byte(testSecret)), true},
+		{"without an expiry", sign(t, jwt.SigningMethodHS256, "", with("exp", nil), []byte(testSecret)), false},
+		{"wrong issuer", sign(t, jwt.SigningMethodHS256, "", with("iss", "other"), []byte(testSecret)), false},
+		{"wrong audience", sign(t, jwt.SigningMethodHS256, "", with("aud", "other"), []byte(testSecret)), false},
+		{"not a bearer token", "Basic abc", false},
+	}
+
+	for _, tt := range tests {
+		claims, err := v.Verify(context.Background(), tt.header)
+		if tt.ok && (err != nil || claims.AdminID() != "adm") {
+			t.Errorf("%s: got %v, %v, want the claims of adm", tt.name, claims, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
+
+func TestVerifyRefusesRS256WithoutJWKS(t *testing.T) {
+	v := newTestVerifier(t, nil)
+
+	if _, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", validClaims(), testRSAKey(t))); err == nil {
+		t.Fatal("got no error for an RS256 token when only JWT_SECRET is set")
+	}
+}
+
+func TestUnknownKidReloadsOncePerMinute(t *testing.T) {
+	key := testRSAKey(t)
+	s := newJWKSServer(t, rsaJWK("k1", &key.PublicKey))
+	v := newTestVerifier(t, s)
+
+	// The set was loaded when the verifier was built, so an unknown kid
+	// does not reload it right away.
+	if _, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k2", validClaims(), key)); err == nil {
+		t.Fatal("got no error for an unknown kid")
+	}
+	if got := s.hits.Load(); got != 1 {
+		t.Fatalf("got %d fetches right after loading, want 1", got)
+	}
+
+	// A minute later the issuer has rotated to k2.
+	s.setKeys(rsaJWK("k2", &key.PublicKey))
+	v.keys.mu.Lock()
+	v.keys.lastReload = time.Now().Add(-2 * time.Minute)
+	v.keys.mu.Unlock()
+
+	if _, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k2", validClaims(), key)); err != nil {
+		t.Fatalf("rotated kid: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k3", validClaims(), key)); err == nil {
+			t.Fatal("got no error for an unknown kid")
+		}
+	}
+	if got := s.hits.Load(); got != 2 {
+		t.Fatalf("got %d fetches, want 2", got)
+	}
+}
+
+func TestEmptyKid(t *testing.T) {
+	key := testRSAKey(t)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newJWKSServer(t, rsaJWK("k1", &key.PublicKey))
+	v := newTestVerifier(t, s)
+	token := sign(t, jwt.SigningMethodRS256, "", validClaims(), key)
+
+	if _, err := v.Verify(context.Background(), token); err != nil {
+		t.Fatalf("one key in the set: %v", err)
+	}
+
+	s.setKeys(rsaJWK("k1", &key.PublicKey), ecJWK("k2", &other.PublicKey))
+	if err := v.keys.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(context.Background(), token); err == nil {
+		t.Fatal("got no error for an empty kid with two keys in the set")
+	}
+}
+
+func TestLoadSkipsUnsupportedKeys(t *testing.T) {
+	key := testRSAKey(t)
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secp := ecJWK("secp", &ec.PublicKey)
+	secp.Crv = "secp256k1"
+
+	s := newJWKSServer(t,
+		jwk{Kty: "OKP", Kid: "ed", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+		secp,
+		ecJWK("ec", &ec.PublicKey),
+		rsaJWK("rsa", &key.PublicKey),
+	)
+	keys := NewKeySet(s.URL)
+	if err := keys.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys.keys) != 2 || keys.keys["ec"] == nil || keys.keys["rsa"] == nil {
+		t.Fatalf("got keys %v, want ec and rsa", keys.keys)
+	}
+
+	s.setKeys(jwk{Kty: "OKP", Kid: "ed", Crv: "Ed25519"}, secp)
+	if err := keys.Load(context.Background()); err == nil {
+		t.Fatal("got no error for a set without supported keys")
+	}
+	if len(keys.keys) != 2 {
+		t.Fatalf("a failed load replaced the keys with %v", keys.keys)
+	}
+}
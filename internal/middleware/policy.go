@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Access int
+
+const (
+	// AccessDenied is the zero value, so a route without a policy is closed.
+	AccessDenied Access = iota
+	AccessPublic
+	AccessAuthenticated
+	AccessPermission
+)
+
+type Policy struct {
+	Access     Access
+	Permission string
+}
+
+func Public() Policy {
+	return Policy{Access: AccessPublic}
+}
+
+func Authenticated() Policy {
+	return Policy{Access: AccessAuthenticated}
+}
+
+func Permission(permission string) Policy {
+	return Policy{Access: AccessPermission, Permission: permission}
+}
+
+// Policies maps "METHOD /path" to the policy of that route. Paths are written
+// exactly as they are registered on the router, including ":param" segments.
+type Policies map[string]Policy
+
+// Lookup finds the policy for a request. Routes are matched segment by
+// segment and literal segments win over parameters, mirroring how the
+// router resolves /drivers/verified/:id next to /drivers/:id.
+func (p Policies) Lookup(method, path string) (Policy, bool) {
+	if method == fiber.MethodHead {
+		method = fiber.MethodGet
+	}
+
+	segments := splitPath(path)
+	best, bestScore, found := Policy{}, -1, false
+
+	for key, policy := range p {
+		keyMethod, keyPath, ok := strings.Cut(key, " ")
+		if !ok || keyMethod != method {
+			continue
+		}
+
+		score, ok := matchSegments(splitPath(keyPath), segments)
+		if ok && score > bestScore {
+			best, bestScore, found = policy, score, true
+		}
+	}
+
+	return best, found
+}
+
+// Verify returns an error naming every registered route that has no policy.
+func (p Policies) Verify(routes []fiber.Route) error {
+	var missing []string
+
+	for _, route := range routes {
+		method := route.Method
+		if method == fiber.MethodHead {
+			method = fiber.MethodGet
+		}
+
+		if _, ok := p[method+" "+route.Path]; !ok {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes without access policy: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func matchSegments(pattern, segments []string) (int, bool) {
+	if len(pattern) != len(segments) {
+		return 0, false
+	}
+
+	score := 0
+	for i := range pattern {
+		switch {
+		case strings.HasPrefix(pattern[i], ":"):
+			if segments[i] == "" {
+				return 0, false
+			}
+		case strings.EqualFold(pattern[i], segments[i]):
+			score++
+		default:
+			return 0, false
+		}
+	}
+
+	return score, true
+}
@@ -0,0 +1,402 @@
+// Package migrations applies the numbered SQL migrations embedded in the
+// binary. Each migration is a pair of files, NNNN_name.up.sql and
+// NNNN_name.down.sql, in the directory of the database dialect. Applied
+// versions are recorded in the schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+var (
+	// ErrSchemaBehind is returned by Check when migrations are pending.
+	ErrSchemaBehind = errors.New("database schema is behind, run the migrate up command")
+
+	// ErrDirty means a migration failed halfway. MySQL cannot roll back DDL,
+	// so the schema has to be repaired by hand and the version row removed.
+	ErrDirty = errors.New("a migration failed halfway and needs manual repair")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// statementEnd splits a migration file into statements. Drivers do not run
+// several statements in one call unless explicitly allowed to.
+var statementEnd = regexp.MustCompile(`;[ \t]*(\r?\n|$)`)
+
+// A statement with semicolons at the end of its own lines, such as a trigger
+// or function body, goes between a "-- begin statement" and an
+// "-- end statement" line and is run whole.
+var (
+	blockBegin = regexp.MustCompile(`(?m)^--[ \t]*begin statement[ \t]*\r?$`)
+	blockEnd   = regexp.MustCompile(`(?m)^--[ \t]*end statement[ \t]*\r?$`)
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	Dirty     bool       `json:"dirty"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// schemaMigration is a row of schema_migrations.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	dialect    string
+	migrations []Migration
+}
+
+// New loads the migrations for the dialect of db.
+func New(db *gorm.DB) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+
+	migrations, err := load(files, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q: %w", dir, err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := statements(string(body)); err != nil {
+			return nil, fmt.Errorf("migration file %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns the ones applied.
+func (m *Migrator) Up(c context.Context) (applied []Migration, err error) {
+	err = m.locked(c, func(tx *gorm.DB) error {
+		done, err := m.clean(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			if err := m.run(tx, migration, migration.Up, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m *Migrator) Down(c context.Context, steps int) (reverted []Migration, err error) {
+	err = m.locked(c, func(tx *gorm.DB) error {
+		done, err := m.clean(tx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+
+			if err := m.run(tx, migration, migration.Down, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status(c context.Context) (res []Status, err error) {
+	tx := m.db.WithContext(c)
+
+	if err := m.ensureTable(tx); err != nil {
+		return nil, err
+	}
+
+	done, err := m.applied(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := done[migration.Version]; ok {
+			status.Applied = !row.Dirty
+			status.Dirty = row.Dirty
+			status.AppliedAt = row.AppliedAt
+		}
+		res = append(res, status)
+	}
+
+	return res, nil
+}
+
+// Check returns ErrSchemaBehind or ErrDirty unless every migration this
+// binary knows about has been applied.
+func (m *Migrator) Check(c context.Context) error {
+	status, err := m.Status(c)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range status {
+		if s.Dirty {
+			return fmt.Errorf("migration %04d_%s: %w", s.Version, s.Name, ErrDirty)
+		}
+		if !s.Applied {
+			return fmt.Errorf("migration %04d_%s is pending: %w", s.Version, s.Name, ErrSchemaBehind)
+		}
+	}
+
+	return nil
+}
+
+// run executes one direction of a migration. The version row is written as
+// dirty first, so a failure halfway is visible to every instance.
+func (m *Migrator) run(tx *gorm.DB, migration Migration, body string, up bool) error {
+	now := time.Now()
+	row := schemaMigration{Version: migration.Version, Name: migration.Name, Dirty: true, AppliedAt: &now}
+
+	if err := tx.Save(&row).Error; err != nil {
+		return err
+	}
+
+	parts, err := statements(body)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range parts {
+		if isBlank(statement) {
+			continue
+		}
+		if err := tx.Exec(statement).Error; err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	if !up {
+		return tx.Delete(&row).Error
+	}
+
+	return tx.Model(&row).Update("dirty", false).Error
+}
+
+func (m *Migrator) applied(tx *gorm.DB) (map[int]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	done := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+
+	return done, nil
+}
+
+// clean returns the applied versions, refusing to go on from a dirty one.
+func (m *Migrator) clean(tx *gorm.DB) (map[int]schemaMigration, error) {
+	done, err := m.applied(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range done {
+		if row.Dirty {
+			return nil, fmt.Errorf("migration %04d_%s: %w", row.Version, row.Name, ErrDirty)
+		}
+	}
+
+	return done, nil
+}
+
+func (m *Migrator) ensureTable(tx *gorm.DB) error {
+	return tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		dirty BOOLEAN NOT NULL DEFAULT FALSE,
+		applied_at TIMESTAMP NULL
+	)`).Error
+}
+
+// locked runs fn on a single connection that holds the migration lock, so
+// instances starting at the same time do not migrate twice.
+func (m *Migrator) locked(c context.Context, fn func(tx *gorm.DB) error) error {
+	return m.db.WithContext(c).Connection(func(conn *gorm.DB) error {
+		// Connection hands back a shared statement; a new session keeps the
+		// conditions of one query from leaking into the next.
+		tx := conn.Session(&gorm.Session{NewDB: true})
+
+		if err := m.lock(tx); err != nil {
+			return err
+		}
+		defer m.unlock(tx)
+
+		if err := m.ensureTable(tx); err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+const lockName = "schema_migrations"
+
+// lockKey is the Postgres advisory lock key, which has to be a number.
+const lockKey = 0x6d696b72
+
+// lock takes a lock held by the connection. SQLite needs none, since it
+// allows a single writer at a time anyway.
+func (m *Migrator) lock(tx *gorm.DB) error {
+	switch m.dialect {
+	case "mysql":
+		var got *int
+		if err := tx.Raw("SELECT GET_LOCK(?, 60)", lockName).Row().Scan(&got); err != nil {
+			return err
+		}
+		if got == nil || *got != 1 {
+			return errors.New("timed out waiting for the migration lock")
+		}
+	case "postgres":
+		return tx.Exec("SELECT pg_advisory_lock(?)", lockKey).Error
+	}
+
+	return nil
+}
+
+func (m *Migrator) unlock(tx *gorm.DB) {
+	switch m.dialect {
+	case "mysql":
+		tx.Exec("SELECT RELEASE_LOCK(?)", lockName)
+	case "postgres":
+		tx.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+	}
+}
+
+// isBlank reports whether statement holds nothing but comments.
+// statements splits body into the statements to run one by one.
+func statements(body string) ([]string, error) {
+	var res []string
+	for {
+		begin := blockBegin.FindStringIndex(body)
+		if begin == nil {
+			return append(res, statementEnd.Split(body, -1)...), nil
+		}
+		res = append(res, statementEnd.Split(body[:begin[0]], -1)...)
+		body = body[begin[1]:]
+
+		end := blockEnd.FindStringIndex(body)
+		if end == nil {
+			return nil, errors.New("begin statement without end statement")
+		}
+		res = append(res, strings.TrimSuffix(strings.TrimSpace(body[:end[0]]), ";"))
+		body = body[end[1]:]
+	}
+}
+
+func isBlank(statement string) bool {
+	for _, line := range strings.Split(statement, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+
+	return true
+}
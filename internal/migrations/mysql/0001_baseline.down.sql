@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS `transactions`;
+DROP TABLE IF EXISTS `reviews`;
+DROP TABLE IF EXISTS `blocked_accounts`;
+DROP TABLE IF EXISTS `reset_passwords`;
+DROP TABLE IF EXISTS `driver_details`;
+DROP TABLE IF EXISTS `passenger_details`;
+DROP TABLE IF EXISTS `admins`;
+DROP TABLE IF EXISTS `users`;
+DROP TABLE IF EXISTS `routes`;
@@ -0,0 +1,93 @@
+-- Schema as created by AutoMigrate before migrations were introduced. Every
+-- statement is IF NOT EXISTS so existing databases can adopt it unchanged.
+CREATE TABLE IF NOT EXISTS `routes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `route_name` varchar(255),
+  `amount` int,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` varchar(255),
+  `email` varchar(255),
+  `password` longtext,
+  `role` enum('admin','user','driver'),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_users_email` UNIQUE (`email`)
+);
+
+CREATE TABLE IF NOT EXISTS `admins` (
+  `id` varchar(255),
+  `name` varchar(255),
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_users_admin_detail` FOREIGN KEY (`id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `passenger_details` (
+  `id` varchar(255),
+  `name` varchar(255),
+  `date_of_birth` date,
+  `age` bigint,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_users_passenger_detail` FOREIGN KEY (`id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `driver_details` (
+  `id` varchar(255),
+  `name` varchar(255),
+  `phone_number` varchar(255),
+  `route_id` bigint unsigned,
+  `license_number` varchar(255),
+  `sim` varchar(255),
+  `status` varchar(255),
+  `verified` boolean DEFAULT false,
+  `available_seats` bigint,
+  `qris_data` longtext,
+  `profile_picture` varchar(255),
+  `ktp` varchar(255),
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_users_driver_detail` FOREIGN KEY (`id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `fk_driver_details_route` FOREIGN KEY (`route_id`) REFERENCES `routes`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `reset_passwords` (
+  `id` bigint AUTO_INCREMENT,
+  `user_id` varchar(255),
+  `code` varchar(255),
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_reset_passwords_user_id` UNIQUE (`user_id`),
+  CONSTRAINT `fk_reset_passwords_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `blocked_accounts` (
+  `id` bigint AUTO_INCREMENT,
+  `user_id` varchar(255),
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_blocked_accounts_user_id` UNIQUE (`user_id`),
+  CONSTRAINT `fk_blocked_accounts_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `reviews` (
+  `id` bigint AUTO_INCREMENT,
+  `passenger_id` varchar(255),
+  `driver_id` varchar(255),
+  `comment` varchar(255),
+  `star` bigint,
+  `created_at` timestamp DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_reviews_passenger` FOREIGN KEY (`passenger_id`) REFERENCES `passenger_details`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_reviews_driver` FOREIGN KEY (`driver_id`) REFERENCES `driver_details`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `transactions` (
+  `id` bigint AUTO_INCREMENT,
+  `passenger_id` varchar(255),
+  `driver_id` varchar(255),
+  `amount` int,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_transactions_passenger` FOREIGN KEY (`passenger_id`) REFERENCES `users`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_transactions_driver` FOREIGN KEY (`driver_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+CREATE TABLE `revoked_tokens` (
+  `jti` varchar(255),
+  `expires_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`jti`)
+);
+
+CREATE TABLE `revoked_subjects` (
+  `subject` varchar(255),
+  `revoked_before` datetime(3) NULL,
+  PRIMARY KEY (`subject`)
+);
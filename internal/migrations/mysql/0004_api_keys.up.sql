@@ -0,0 +1,14 @@
+CREATE TABLE `api_keys` (
+  `id` varchar(36),
+  `name` varchar(255),
+  `prefix` varchar(16),
+  `hash` varchar(64),
+  `scopes` varchar(1024),
+  `created_by` varchar(255),
+  `expires_at` datetime(3) NULL,
+  `last_used_at` datetime(3) NULL,
+  `revoked_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_api_keys_prefix` (`prefix`)
+);
@@ -0,0 +1,17 @@
+CREATE TABLE `audit_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `actor_type` varchar(32),
+  `actor_id` varchar(255),
+  `action` varchar(64),
+  `target_type` varchar(64),
+  `target_id` varchar(255),
+  `ip` varchar(64),
+  `before` text,
+  `after` text,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_audit_logs_actor_id` (`actor_id`),
+  INDEX `idx_audit_logs_action` (`action`),
+  INDEX `idx_audit_logs_target` (`target_type`, `target_id`),
+  INDEX `idx_audit_logs_created_at` (`created_at`)
+);
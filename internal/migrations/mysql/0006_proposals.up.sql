@@ -0,0 +1,15 @@
+CREATE TABLE `proposals` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `action` varchar(64),
+  `target_id` varchar(255),
+  `payload` text,
+  `status` varchar(16),
+  `requested_by` varchar(255),
+  `decided_by` varchar(255),
+  `note` varchar(255),
+  `expires_at` datetime(3) NULL,
+  `decided_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_proposals_status` (`status`)
+);
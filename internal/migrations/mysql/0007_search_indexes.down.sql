@@ -0,0 +1,5 @@
+DROP INDEX `ft_reviews_comment` ON `reviews`;
+DROP INDEX `ft_routes_route_name` ON `routes`;
+DROP INDEX `ft_users_email` ON `users`;
+DROP INDEX `ft_passenger_details_name` ON `passenger_details`;
+DROP INDEX `ft_driver_details_name` ON `driver_details`;
@@ -0,0 +1,7 @@
+-- One FULLTEXT index per column, because MATCH must name exactly the
+-- columns of an index. See models.FullTextIndexes.
+CREATE FULLTEXT INDEX `ft_driver_details_name` ON `driver_details` (`name`);
+CREATE FULLTEXT INDEX `ft_passenger_details_name` ON `passenger_details` (`name`);
+CREATE FULLTEXT INDEX `ft_users_email` ON `users` (`email`);
+CREATE FULLTEXT INDEX `ft_routes_route_name` ON `routes` (`route_name`);
+CREATE FULLTEXT INDEX `ft_reviews_comment` ON `reviews` (`comment`);
@@ -0,0 +1,15 @@
+ALTER TABLE `transactions`
+  DROP FOREIGN KEY `fk_transactions_passenger`,
+  DROP FOREIGN KEY `fk_transactions_driver`;
+ALTER TABLE `transactions`
+  ADD CONSTRAINT `fk_transactions_passenger` FOREIGN KEY (`passenger_id`) REFERENCES `users`(`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `fk_transactions_driver` FOREIGN KEY (`driver_id`) REFERENCES `users`(`id`) ON DELETE CASCADE;
+
+DROP INDEX `idx_passenger_details_deleted_at` ON `passenger_details`;
+ALTER TABLE `passenger_details` DROP COLUMN `purged_at`, DROP COLUMN `deleted_at`;
+
+DROP INDEX `idx_driver_details_deleted_at` ON `driver_details`;
+ALTER TABLE `driver_details` DROP COLUMN `purged_at`, DROP COLUMN `deleted_at`;
+
+DROP INDEX `idx_users_deleted_at` ON `users`;
+ALTER TABLE `users` DROP COLUMN `deleted_at`;
@@ -0,0 +1,20 @@
+ALTER TABLE `users` ADD COLUMN `deleted_at` datetime(3) NULL;
+CREATE INDEX `idx_users_deleted_at` ON `users` (`deleted_at`);
+
+ALTER TABLE `driver_details`
+  ADD COLUMN `deleted_at` datetime(3) NULL,
+  ADD COLUMN `purged_at` datetime(3) NULL;
+CREATE INDEX `idx_driver_details_deleted_at` ON `driver_details` (`deleted_at`);
+
+ALTER TABLE `passenger_details`
+  ADD COLUMN `deleted_at` datetime(3) NULL,
+  ADD COLUMN `purged_at` datetime(3) NULL;
+CREATE INDEX `idx_passenger_details_deleted_at` ON `passenger_details` (`deleted_at`);
+
+-- Purging an account must not take its trips, and the revenue in them, along.
+ALTER TABLE `transactions`
+  DROP FOREIGN KEY `fk_transactions_passenger`,
+  DROP FOREIGN KEY `fk_transactions_driver`;
+ALTER TABLE `transactions`
+  ADD CONSTRAINT `fk_transactions_passenger` FOREIGN KEY (`passenger_id`) REFERENCES `users`(`id`) ON DELETE SET NULL,
+  ADD CONSTRAINT `fk_transactions_driver` FOREIGN KEY (`driver_id`) REFERENCES `users`(`id`) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE `driver_details` DROP COLUMN `version`;
+ALTER TABLE `routes` DROP COLUMN `version`;
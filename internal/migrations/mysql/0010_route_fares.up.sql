@@ -0,0 +1,19 @@
+-- Fare history of every route. Fares of a route never overlap: each one runs
+-- from effective_from until the next starts, and the last has no effective_to.
+CREATE TABLE `route_fares` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `route_id` bigint unsigned NOT NULL,
+  `amount` int NOT NULL,
+  `effective_from` datetime(3) NOT NULL,
+  `effective_to` datetime(3) NULL,
+  `created_by` varchar(255),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_route_fares_route_from` (`route_id`, `effective_from`),
+  CONSTRAINT `fk_route_fares_route` FOREIGN KEY (`route_id`) REFERENCES `routes`(`id`) ON DELETE CASCADE
+);
+
+-- Nothing is known about earlier fares, so the current one is taken to have
+-- always applied.
+INSERT INTO `route_fares` (`route_id`, `amount`, `effective_from`, `created_by`, `created_at`)
+SELECT `id`, COALESCE(`amount`, 0), '1970-01-01 00:00:00', 'migration', CURRENT_TIMESTAMP FROM `routes`;
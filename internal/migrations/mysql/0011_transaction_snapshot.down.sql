@@ -0,0 +1,4 @@
+ALTER TABLE `transactions` DROP FOREIGN KEY `fk_transactions_route`;
+DROP INDEX `idx_transactions_route_created` ON `transactions`;
+ALTER TABLE `transactions` DROP COLUMN `fare`;
+ALTER TABLE `transactions` DROP COLUMN `route_id`;
@@ -0,0 +1,2 @@
+ALTER TABLE `transactions` DROP FOREIGN KEY `fk_transactions_route`;
+ALTER TABLE `transactions` ADD CONSTRAINT `fk_transactions_route` FOREIGN KEY (`route_id`) REFERENCES `routes`(`id`);
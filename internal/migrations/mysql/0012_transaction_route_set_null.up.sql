@@ -0,0 +1,3 @@
+-- Deleting a route keeps its trips, and the revenue in them, without a route.
+ALTER TABLE `transactions` DROP FOREIGN KEY `fk_transactions_route`;
+ALTER TABLE `transactions` ADD CONSTRAINT `fk_transactions_route` FOREIGN KEY (`route_id`) REFERENCES `routes`(`id`) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+-- A trip inserted without a route or fare takes the route its driver is on
+-- and the fare in effect on that route when the trip was made, so writers
+-- that predate 0011 keep the snapshot filled in. A route without fares
+-- charges routes.amount.
+CREATE TRIGGER `transactions_snapshot` BEFORE INSERT ON `transactions` FOR EACH ROW
+SET
+  NEW.`route_id` = COALESCE(NEW.`route_id`, (
+    SELECT d.`route_id` FROM `driver_details` d WHERE d.`id` = NEW.`driver_id`
+  )),
+  NEW.`fare` = COALESCE(NEW.`fare`, (
+    SELECT f.`amount` FROM `route_fares` f
+    WHERE f.`route_id` = NEW.`route_id`
+      AND f.`effective_from` <= COALESCE(NEW.`created_at`, CURRENT_TIMESTAMP)
+      AND (f.`effective_to` IS NULL OR f.`effective_to` > COALESCE(NEW.`created_at`, CURRENT_TIMESTAMP))
+  ), (
+    SELECT r.`amount` FROM `routes` r WHERE r.`id` = NEW.`route_id`
+  ));
@@ -0,0 +1,84 @@
+-- The schema as it was when migrations were introduced, so every dialect
+-- starts from the same version as MySQL.
+CREATE TABLE IF NOT EXISTS routes (
+  id bigserial PRIMARY KEY,
+  route_name varchar(255),
+  amount integer
+);
+
+CREATE TABLE IF NOT EXISTS users (
+  id varchar(255) PRIMARY KEY,
+  email varchar(255),
+  password text,
+  role varchar(16) CHECK (role IN ('admin', 'user', 'driver')),
+  created_at timestamptz NULL,
+  updated_at timestamptz NULL,
+  CONSTRAINT uni_users_email UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS admins (
+  id varchar(255) PRIMARY KEY,
+  name varchar(255),
+  CONSTRAINT fk_users_admin_detail FOREIGN KEY (id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS passenger_details (
+  id varchar(255) PRIMARY KEY,
+  name varchar(255),
+  date_of_birth date,
+  age bigint,
+  CONSTRAINT fk_users_passenger_detail FOREIGN KEY (id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS driver_details (
+  id varchar(255) PRIMARY KEY,
+  name varchar(255),
+  phone_number varchar(255),
+  route_id bigint,
+  license_number varchar(255),
+  sim varchar(255),
+  status varchar(255),
+  verified boolean DEFAULT false,
+  available_seats bigint,
+  qris_data text,
+  profile_picture varchar(255),
+  ktp varchar(255),
+  CONSTRAINT fk_users_driver_detail FOREIGN KEY (id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT fk_driver_details_route FOREIGN KEY (route_id) REFERENCES routes(id)
+);
+
+CREATE TABLE IF NOT EXISTS reset_passwords (
+  id bigserial PRIMARY KEY,
+  user_id varchar(255),
+  code varchar(255),
+  CONSTRAINT uni_reset_passwords_user_id UNIQUE (user_id),
+  CONSTRAINT fk_reset_passwords_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS blocked_accounts (
+  id bigserial PRIMARY KEY,
+  user_id varchar(255),
+  CONSTRAINT uni_blocked_accounts_user_id UNIQUE (user_id),
+  CONSTRAINT fk_blocked_accounts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS reviews (
+  id bigserial PRIMARY KEY,
+  passenger_id varchar(255),
+  driver_id varchar(255),
+  comment varchar(255),
+  star bigint,
+  created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_reviews_passenger FOREIGN KEY (passenger_id) REFERENCES passenger_details(id) ON DELETE CASCADE,
+  CONSTRAINT fk_reviews_driver FOREIGN KEY (driver_id) REFERENCES driver_details(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+  id bigserial PRIMARY KEY,
+  passenger_id varchar(255),
+  driver_id varchar(255),
+  amount integer,
+  created_at timestamptz NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_transactions_passenger FOREIGN KEY (passenger_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_transactions_driver FOREIGN KEY (driver_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+ALTER TABLE admins
+  DROP CONSTRAINT fk_admins_role,
+  DROP COLUMN role_name;
+
+DROP TABLE role_permissions;
+DROP TABLE roles;
+DROP TABLE permissions;
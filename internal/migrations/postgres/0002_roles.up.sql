@@ -0,0 +1,23 @@
+CREATE TABLE permissions (
+  name varchar(64) PRIMARY KEY
+);
+
+CREATE TABLE roles (
+  name varchar(64) PRIMARY KEY
+);
+
+CREATE TABLE role_permissions (
+  role_name varchar(64),
+  permission_name varchar(64),
+  PRIMARY KEY (role_name, permission_name),
+  CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_name) REFERENCES roles(name) ON DELETE CASCADE,
+  CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_name) REFERENCES permissions(name) ON DELETE CASCADE
+);
+
+-- Existing admins default to viewer, so the role must exist before the
+-- foreign key is added. Permissions are seeded from models.DefaultRoles.
+INSERT INTO roles (name) VALUES ('super-admin'), ('finance'), ('moderator'), ('viewer');
+
+ALTER TABLE admins
+  ADD COLUMN role_name varchar(64) DEFAULT 'viewer',
+  ADD CONSTRAINT fk_admins_role FOREIGN KEY (role_name) REFERENCES roles(name);
@@ -0,0 +1,10 @@
+CREATE TABLE revoked_tokens (
+  jti varchar(255) PRIMARY KEY,
+  expires_at timestamptz NULL,
+  created_at timestamptz NULL
+);
+
+CREATE TABLE revoked_subjects (
+  subject varchar(255) PRIMARY KEY,
+  revoked_before timestamptz NULL
+);
@@ -0,0 +1,14 @@
+CREATE TABLE api_keys (
+  id varchar(36) PRIMARY KEY,
+  name varchar(255),
+  prefix varchar(16),
+  hash varchar(64),
+  scopes varchar(1024),
+  created_by varchar(255),
+  expires_at timestamptz NULL,
+  last_used_at timestamptz NULL,
+  revoked_at timestamptz NULL,
+  created_at timestamptz NULL
+);
+
+CREATE UNIQUE INDEX idx_api_keys_prefix ON api_keys (prefix);
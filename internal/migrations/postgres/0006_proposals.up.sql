@@ -0,0 +1,15 @@
+CREATE TABLE proposals (
+  id bigserial PRIMARY KEY,
+  action varchar(64),
+  target_id varchar(255),
+  payload text,
+  status varchar(16),
+  requested_by varchar(255),
+  decided_by varchar(255),
+  note varchar(255),
+  expires_at timestamptz NULL,
+  decided_at timestamptz NULL,
+  created_at timestamptz NULL
+);
+
+CREATE INDEX idx_proposals_status ON proposals (status);
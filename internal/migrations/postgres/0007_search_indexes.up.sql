@@ -0,0 +1,2 @@
+-- FULLTEXT indexes are MySQL only; search falls back to LIKE here. The
+-- version is kept so every dialect numbers its migrations the same way.
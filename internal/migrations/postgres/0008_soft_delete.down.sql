@@ -0,0 +1,14 @@
+ALTER TABLE transactions
+  DROP CONSTRAINT fk_transactions_passenger,
+  DROP CONSTRAINT fk_transactions_driver,
+  ADD CONSTRAINT fk_transactions_passenger FOREIGN KEY (passenger_id) REFERENCES users(id) ON DELETE CASCADE,
+  ADD CONSTRAINT fk_transactions_driver FOREIGN KEY (driver_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DROP INDEX idx_passenger_details_deleted_at;
+ALTER TABLE passenger_details DROP COLUMN purged_at, DROP COLUMN deleted_at;
+
+DROP INDEX idx_driver_details_deleted_at;
+ALTER TABLE driver_details DROP COLUMN purged_at, DROP COLUMN deleted_at;
+
+DROP INDEX idx_users_deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
@@ -0,0 +1,19 @@
+ALTER TABLE users ADD COLUMN deleted_at timestamptz NULL;
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+ALTER TABLE driver_details
+  ADD COLUMN deleted_at timestamptz NULL,
+  ADD COLUMN purged_at timestamptz NULL;
+CREATE INDEX idx_driver_details_deleted_at ON driver_details (deleted_at);
+
+ALTER TABLE passenger_details
+  ADD COLUMN deleted_at timestamptz NULL,
+  ADD COLUMN purged_at timestamptz NULL;
+CREATE INDEX idx_passenger_details_deleted_at ON passenger_details (deleted_at);
+
+-- Purging an account must not take its trips, and the revenue in them, along.
+ALTER TABLE transactions
+  DROP CONSTRAINT fk_transactions_passenger,
+  DROP CONSTRAINT fk_transactions_driver,
+  ADD CONSTRAINT fk_transactions_passenger FOREIGN KEY (passenger_id) REFERENCES users(id) ON DELETE SET NULL,
+  ADD CONSTRAINT fk_transactions_driver FOREIGN KEY (driver_id) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+-- Row versions for optimistic concurrency: every dashboard change bumps them
+-- and a change based on an older version is refused.
+ALTER TABLE routes ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE driver_details ADD COLUMN version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP INDEX idx_transactions_route_created;
+ALTER TABLE transactions DROP COLUMN fare;
+ALTER TABLE transactions DROP COLUMN route_id;
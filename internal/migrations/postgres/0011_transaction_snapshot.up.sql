@@ -0,0 +1,19 @@
+-- Trips keep the route they were made on and the fare in effect then, so
+-- moving a driver to another route or changing a fare leaves history alone.
+-- The writer of transactions fills both in; rows from before are backfilled.
+ALTER TABLE transactions ADD COLUMN route_id bigint NULL;
+ALTER TABLE transactions ADD COLUMN fare integer NULL;
+CREATE INDEX idx_transactions_route_created ON transactions (route_id, created_at);
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_route FOREIGN KEY (route_id) REFERENCES routes(id);
+
+-- The route a driver is on now is all that is known about older trips.
+UPDATE transactions SET route_id = (
+  SELECT d.route_id FROM driver_details d WHERE d.id = transactions.driver_id
+);
+
+UPDATE transactions SET fare = (
+  SELECT f.amount FROM route_fares f
+  WHERE f.route_id = transactions.route_id
+    AND f.effective_from <= transactions.created_at
+    AND (f.effective_to IS NULL OR f.effective_to > transactions.created_at)
+) WHERE route_id IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+  DROP CONSTRAINT fk_transactions_route,
+  ADD CONSTRAINT fk_transactions_route FOREIGN KEY (route_id) REFERENCES routes(id);
@@ -0,0 +1,4 @@
+-- Deleting a route keeps its trips, and the revenue in them, without a route.
+ALTER TABLE transactions
+  DROP CONSTRAINT fk_transactions_route,
+  ADD CONSTRAINT fk_transactions_route FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP TRIGGER transactions_snapshot ON transactions;
+DROP FUNCTION transactions_snapshot();
@@ -0,0 +1,29 @@
+-- A trip inserted without a route or fare takes the route its driver is on
+-- and the fare in effect on that route when the trip was made, so writers
+-- that predate 0011 keep the snapshot filled in. A route without fares
+-- charges routes.amount.
+-- begin statement
+CREATE FUNCTION transactions_snapshot() RETURNS trigger AS $$
+BEGIN
+  IF NEW.route_id IS NULL THEN
+    NEW.route_id := (SELECT d.route_id FROM driver_details d WHERE d.id = NEW.driver_id);
+  END IF;
+
+  IF NEW.fare IS NULL THEN
+    NEW.fare := COALESCE((
+      SELECT f.amount FROM route_fares f
+      WHERE f.route_id = NEW.route_id
+        AND f.effective_from <= COALESCE(NEW.created_at, CURRENT_TIMESTAMP)
+        AND (f.effective_to IS NULL OR f.effective_to > COALESCE(NEW.created_at, CURRENT_TIMESTAMP))
+    ), (
+      SELECT r.amount FROM routes r WHERE r.id = NEW.route_id
+    ));
+  END IF;
+
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- end statement
+
+CREATE TRIGGER transactions_snapshot BEFORE INSERT ON transactions
+FOR EACH ROW EXECUTE FUNCTION transactions_snapshot();
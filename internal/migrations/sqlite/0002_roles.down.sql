@@ -0,0 +1,12 @@
+CREATE TABLE admins_old (
+  id varchar(255) PRIMARY KEY,
+  name varchar(255),
+  CONSTRAINT fk_users_admin_detail FOREIGN KEY (id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO admins_old (id, name) SELECT id, name FROM admins;
+DROP TABLE admins;
+ALTER TABLE admins_old RENAME TO admins;
+
+DROP TABLE role_permissions;
+DROP TABLE roles;
+DROP TABLE permissions;
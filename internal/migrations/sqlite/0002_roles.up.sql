@@ -0,0 +1,32 @@
+CREATE TABLE permissions (
+  name varchar(64) PRIMARY KEY
+);
+
+CREATE TABLE roles (
+  name varchar(64) PRIMARY KEY
+);
+
+CREATE TABLE role_permissions (
+  role_name varchar(64),
+  permission_name varchar(64),
+  PRIMARY KEY (role_name, permission_name),
+  CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_name) REFERENCES roles(name) ON DELETE CASCADE,
+  CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_name) REFERENCES permissions(name) ON DELETE CASCADE
+);
+
+-- Existing admins default to viewer, so the role must exist before the
+-- foreign key is added. Permissions are seeded from models.DefaultRoles.
+INSERT INTO roles (name) VALUES ('super-admin'), ('finance'), ('moderator'), ('viewer');
+
+-- SQLite cannot add a column that references another table with a non-null
+-- default, so admins is rebuilt instead.
+CREATE TABLE admins_new (
+  id varchar(255) PRIMARY KEY,
+  name varchar(255),
+  role_name varchar(64) DEFAULT 'viewer',
+  CONSTRAINT fk_users_admin_detail FOREIGN KEY (id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT fk_admins_role FOREIGN KEY (role_name) REFERENCES roles(name)
+);
+INSERT INTO admins_new (id, name) SELECT id, name FROM admins;
+DROP TABLE admins;
+ALTER TABLE admins_new RENAME TO admins;
@@ -0,0 +1,17 @@
+CREATE TABLE audit_logs (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  actor_type varchar(32),
+  actor_id varchar(255),
+  action varchar(64),
+  target_type varchar(64),
+  target_id varchar(255),
+  ip varchar(64),
+  "before" text,
+  "after" text,
+  created_at datetime NULL
+);
+
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX idx_audit_logs_target ON audit_logs (target_type, target_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
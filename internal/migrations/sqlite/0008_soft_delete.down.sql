@@ -0,0 +1,23 @@
+CREATE TABLE transactions_old (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  passenger_id varchar(255),
+  driver_id varchar(255),
+  amount integer,
+  created_at datetime NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_transactions_passenger FOREIGN KEY (passenger_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_transactions_driver FOREIGN KEY (driver_id) REFERENCES users(id) ON DELETE CASCADE
+);
+INSERT INTO transactions_old SELECT id, passenger_id, driver_id, amount, created_at FROM transactions;
+DROP TABLE transactions;
+ALTER TABLE transactions_old RENAME TO transactions;
+
+DROP INDEX idx_passenger_details_deleted_at;
+ALTER TABLE passenger_details DROP COLUMN purged_at;
+ALTER TABLE passenger_details DROP COLUMN deleted_at;
+
+DROP INDEX idx_driver_details_deleted_at;
+ALTER TABLE driver_details DROP COLUMN purged_at;
+ALTER TABLE driver_details DROP COLUMN deleted_at;
+
+DROP INDEX idx_users_deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
@@ -0,0 +1,25 @@
+ALTER TABLE users ADD COLUMN deleted_at datetime NULL;
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+ALTER TABLE driver_details ADD COLUMN deleted_at datetime NULL;
+ALTER TABLE driver_details ADD COLUMN purged_at datetime NULL;
+CREATE INDEX idx_driver_details_deleted_at ON driver_details (deleted_at);
+
+ALTER TABLE passenger_details ADD COLUMN deleted_at datetime NULL;
+ALTER TABLE passenger_details ADD COLUMN purged_at datetime NULL;
+CREATE INDEX idx_passenger_details_deleted_at ON passenger_details (deleted_at);
+
+-- Purging an account must not take its trips, and the revenue in them, along.
+-- SQLite cannot alter a foreign key, so transactions is rebuilt.
+CREATE TABLE transactions_new (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  passenger_id varchar(255),
+  driver_id varchar(255),
+  amount integer,
+  created_at datetime NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_transactions_passenger FOREIGN KEY (passenger_id) REFERENCES users(id) ON DELETE SET NULL,
+  CONSTRAINT fk_transactions_driver FOREIGN KEY (driver_id) REFERENCES users(id) ON DELETE SET NULL
+);
+INSERT INTO transactions_new SELECT id, passenger_id, driver_id, amount, created_at FROM transactions;
+DROP TABLE transactions;
+ALTER TABLE transactions_new RENAME TO transactions;
@@ -0,0 +1,19 @@
+-- Fare history of every route. Fares of a route never overlap: each one runs
+-- from effective_from until the next starts, and the last has no effective_to.
+CREATE TABLE route_fares (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  route_id integer NOT NULL,
+  amount integer NOT NULL,
+  effective_from datetime NOT NULL,
+  effective_to datetime NULL,
+  created_by varchar(255),
+  created_at datetime NULL,
+  CONSTRAINT fk_route_fares_route FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_route_fares_route_from ON route_fares (route_id, effective_from);
+
+-- Nothing is known about earlier fares, so the current one is taken to have
+-- always applied.
+INSERT INTO route_fares (route_id, amount, effective_from, created_by, created_at)
+SELECT id, COALESCE(amount, 0), '1970-01-01 00:00:00', 'migration', CURRENT_TIMESTAMP FROM routes;
@@ -0,0 +1,14 @@
+-- SQLite cannot drop a column that is part of a foreign key, so transactions
+-- is rebuilt as it was after 0008.
+CREATE TABLE transactions_new (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  passenger_id varchar(255),
+  driver_id varchar(255),
+  amount integer,
+  created_at datetime NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_transactions_passenger FOREIGN KEY (passenger_id) REFERENCES users(id) ON DELETE SET NULL,
+  CONSTRAINT fk_transactions_driver FOREIGN KEY (driver_id) REFERENCES users(id) ON DELETE SET NULL
+);
+INSERT INTO transactions_new SELECT id, passenger_id, driver_id, amount, created_at FROM transactions;
+DROP TABLE transactions;
+ALTER TABLE transactions_new RENAME TO transactions;
@@ -0,0 +1,18 @@
+-- Deleting a route keeps its trips, and the revenue in them, without a route.
+-- SQLite cannot alter a foreign key, so transactions is rebuilt.
+CREATE TABLE transactions_new (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  passenger_id varchar(255),
+  driver_id varchar(255),
+  amount integer,
+  created_at datetime NULL DEFAULT CURRENT_TIMESTAMP,
+  route_id integer NULL,
+  fare integer NULL,
+  CONSTRAINT fk_transactions_passenger FOREIGN KEY (passenger_id) REFERENCES users(id) ON DELETE SET NULL,
+  CONSTRAINT fk_transactions_driver FOREIGN KEY (driver_id) REFERENCES users(id) ON DELETE SET NULL,
+  CONSTRAINT fk_transactions_route FOREIGN KEY (route_id) REFERENCES routes(id) ON DELETE SET NULL
+);
+INSERT INTO transactions_new SELECT id, passenger_id, driver_id, amount, created_at, route_id, fare FROM transactions;
+DROP TABLE transactions;
+ALTER TABLE transactions_new RENAME TO transactions;
+CREATE INDEX idx_transactions_route_created ON transactions (route_id, created_at);
@@ -0,0 +1 @@
+DROP TRIGGER transactions_snapshot;
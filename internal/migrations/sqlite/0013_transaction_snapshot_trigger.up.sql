@@ -0,0 +1,25 @@
+-- A trip inserted without a route or fare takes the route its driver is on
+-- and the fare in effect on that route when the trip was made, so writers
+-- that predate 0011 keep the snapshot filled in. A route without fares
+-- charges routes.amount. SQLite cannot change a row before it is inserted,
+-- so the trigger updates it right after.
+-- begin statement
+CREATE TRIGGER transactions_snapshot AFTER INSERT ON transactions
+FOR EACH ROW WHEN NEW.route_id IS NULL OR NEW.fare IS NULL
+BEGIN
+  UPDATE transactions SET route_id = COALESCE(route_id, (
+    SELECT d.route_id FROM driver_details d WHERE d.id = NEW.driver_id
+  ))
+  WHERE id = NEW.id;
+
+  UPDATE transactions SET fare = COALESCE((
+    SELECT f.amount FROM route_fares f
+    WHERE f.route_id = transactions.route_id
+      AND f.effective_from <= COALESCE(transactions.created_at, CURRENT_TIMESTAMP)
+      AND (f.effective_to IS NULL OR f.effective_to > COALESCE(transactions.created_at, CURRENT_TIMESTAMP))
+  ), (
+    SELECT r.amount FROM routes r WHERE r.id = transactions.route_id
+  ))
+  WHERE id = NEW.id AND fare IS NULL;
+END;
+-- end statement
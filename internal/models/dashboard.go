@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
@@ -11,6 +13,7 @@ type User struct {
 	Role            string `gorm:"type:enum('admin','user','driver')"`
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt   `gorm:"index"`
 	DriverDetail    DriverDetails    `gorm:"foreignKey:ID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
 	PassengerDetail PassengerDetails `gorm:"foreignKey:ID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
 	AdminDetail     Admin            `gorm:"foreignKey:ID;references:ID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
@@ -28,8 +31,11 @@ type DriverDetails struct {
 	Verified       bool   `gorm:"default:false"`
 	AvailableSeats int
 	QrisData       string
-	ProfilePicture string `gorm:"type:varchar(255)"`
-	KTP            string `gorm:"type:varchar(255)"`
+	ProfilePicture string         `gorm:"type:varchar(255)"`
+	KTP            string         `gorm:"type:varchar(255)"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	PurgedAt       *time.Time
+	Version        uint `gorm:"not null;default:1"`
 }
 
 type PassengerDetails struct {
@@ -37,11 +43,85 @@ type PassengerDetails struct {
 	Name        string    `gorm:"type:varchar(255)"`
 	DateOfBirth time.Time `gorm:"type:date"`
 	Age         int
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	PurgedAt    *time.Time
 }
 
 type Admin struct {
-	ID   string `gorm:"primaryKey;type:varchar(255)"`
-	Name string `gorm:"type:varchar(255)"`
+	ID       string `gorm:"primaryKey;type:varchar(255)"`
+	Name     string `gorm:"type:varchar(255)"`
+	RoleName string `gorm:"type:varchar(64);default:viewer"`
+	Role     Role   `gorm:"foreignKey:RoleName;references:Name"`
+}
+
+type Role struct {
+	Name        string       `gorm:"primaryKey;type:varchar(64)" json:"name"`
+	Permissions []Permission `gorm:"many2many:role_permissions;constraint:OnDelete:CASCADE" json:"permissions"`
+}
+
+type Permission struct {
+	Name string `gorm:"primaryKey;type:varchar(64)" json:"name"`
+}
+
+type RevokedToken struct {
+	JTI       string `gorm:"primaryKey;type:varchar(255)"`
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type RevokedSubject struct {
+	Subject       string `gorm:"primaryKey;type:varchar(255)"`
+	RevokedBefore time.Time
+}
+
+type ApiKey struct {
+	ID         string     `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	Name       string     `gorm:"type:varchar(255)" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);uniqueIndex" json:"prefix"`
+	Hash       string     `gorm:"type:varchar(64)" json:"-"`
+	Scopes     Scopes     `gorm:"type:varchar(1024)" json:"scopes"`
+	CreatedBy  string     `gorm:"type:varchar(255)" json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActorType  string    `gorm:"type:varchar(32)" json:"actor_type"`
+	ActorID    string    `gorm:"type:varchar(255);index" json:"actor_id"`
+	Action     string    `gorm:"type:varchar(64);index" json:"action"`
+	TargetType string    `gorm:"type:varchar(64);index:idx_audit_logs_target,priority:1" json:"target_type"`
+	TargetID   string    `gorm:"type:varchar(255);index:idx_audit_logs_target,priority:2" json:"target_id"`
+	IP         string    `gorm:"type:varchar(64)" json:"ip"`
+	Before     JSON      `gorm:"type:text" json:"before"`
+	After      JSON      `gorm:"type:text" json:"after"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// A proposal being approved is executing while its action runs, and
+// approved once the action went through.
+const (
+	ProposalPending   = "pending"
+	ProposalExecuting = "executing"
+	ProposalApproved  = "approved"
+	ProposalRejected  = "rejected"
+	ProposalExpired   = "expired"
+)
+
+type Proposal struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Action      string     `gorm:"type:varchar(64)" json:"action"`
+	TargetID    string     `gorm:"type:varchar(255)" json:"target_id"`
+	Payload     JSON       `gorm:"type:text" json:"payload"`
+	Status      string     `gorm:"type:varchar(16);index" json:"status"`
+	RequestedBy string     `gorm:"type:varchar(255)" json:"requested_by"`
+	DecidedBy   string     `gorm:"type:varchar(255)" json:"decided_by"`
+	Note        string     `gorm:"type:varchar(255)" json:"note"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	DecidedAt   *time.Time `json:"decided_at"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
 
 type ResetPassword struct {
@@ -61,6 +141,19 @@ type Route struct {
 	ID        uint   `gorm:"primaryKey"`
 	RouteName string `gorm:"type:varchar(255)"`
 	Amount    int    `gorm:"type:int"`
+	Version   uint   `gorm:"not null;default:1"`
+}
+
+// RouteFare is the fare of a route from EffectiveFrom until EffectiveTo. The
+// fare that is in effect last has no EffectiveTo.
+type RouteFare struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	RouteID       uint       `gorm:"not null;uniqueIndex:idx_route_fares_route_from,priority:1" json:"route_id"`
+	Amount        int        `gorm:"type:int;not null" json:"amount"`
+	EffectiveFrom time.Time  `gorm:"not null;uniqueIndex:idx_route_fares_route_from,priority:2" json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	CreatedBy     string     `gorm:"type:varchar(255)" json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
 }
 
 type Review struct {
@@ -74,12 +167,18 @@ type Review struct {
 	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
 }
 
+// Transaction is a trip. RouteID and Fare are the route of the driver and
+// the fare in effect when the trip was made, so later changes to either do
+// not move the trip in history and reports.
 type Transaction struct {
 	ID          int        `gorm:"primaryKey"`
 	PassengerID string     `gorm:"type:varchar(255)"`
-	Passenger   User       `gorm:"foreignKey:PassengerID;references:ID;constraint:OnDelete:CASCADE"`
+	Passenger   User       `gorm:"foreignKey:PassengerID;references:ID;constraint:OnDelete:SET NULL"`
 	DriverID    string     `gorm:"type:varchar(255)"`
-	Driver      User       `gorm:"foreignKey:DriverID;references:ID;constraint:OnDelete:CASCADE"`
+	Driver      User       `gorm:"foreignKey:DriverID;references:ID;constraint:OnDelete:SET NULL"`
+	RouteID     *uint      `gorm:"index:idx_transactions_route_created,priority:1"`
+	Route       Route      `gorm:"foreignKey:RouteID;references:ID;constraint:OnDelete:SET NULL"`
 	Amount      int        `gorm:"type:int"`
-	CreatedAt   *time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	Fare        *int       `gorm:"type:int"`
+	CreatedAt   *time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_transactions_route_created,priority:2"`
 }
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// DatabaseInit connects to the database selected by DB_DRIVER: mysql (the
+// default), postgres or sqlite. For sqlite DB_NAME is the path of the
+// database file.
+func DatabaseInit() *gorm.DB {
+	dialector, err := dialectorFromEnv(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
+	if err != nil {
+		panic(err)
+	}
+
+	// TranslateError turns driver specific errors such as duplicate keys into
+	// the gorm errors the repositories check for.
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+
+	if err != nil {
+		panic(fmt.Errorf("error while connecting database"))
+	}
+
+	log.Printf("Connection Succeed (%s)", dialector.Name())
+
+	return db
+}
+
+// dialectorFromEnv builds the dialector of DB_DRIVER for the server at host
+// and port. Every other setting is shared by the primary and its replicas.
+func dialectorFromEnv(host string, port string) (gorm.Dialector, error) {
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "mysql":
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), host, port, os.Getenv("DB_NAME"))
+		return mysql.Open(dsn), nil
+	case "postgres":
+		sslMode := os.Getenv("DB_SSLMODE")
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", host, port, os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"), sslMode)
+		return postgres.Open(dsn), nil
+	case "sqlite":
+		path := os.Getenv("DB_NAME")
+		if path == "" {
+			path = "mikronet.db"
+		}
+
+		// Foreign keys are off by default in SQLite, and the cascades and
+		// SET NULL rules of the schema depend on them.
+		return sqlite.Open(path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), nil
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q, use mysql, postgres or sqlite", driver)
+	}
+}
@@ -10,24 +10,27 @@ type BlockDriver struct {
 }
 
 type Drivers struct {
-	ID             string `json:"id"`
-	Email          string `json:"email"`
-	Name           string `json:"name"`
-	PhoneNumber    string `json:"phone_number"`
-	LicenseNumber  string `json:"license_number"`
-	SIM            string `json:"sim"`
-	Verified       bool   `json:"verified"`
-	ProfilePicture string `json:"profile_picture"`
-	KTP            string `json:"ktp"`
-	Status         string `json:"status"`
+	ID             string     `json:"id"`
+	Email          string     `json:"email"`
+	Name           string     `json:"name"`
+	PhoneNumber    string     `json:"phone_number"`
+	LicenseNumber  string     `json:"license_number"`
+	SIM            string     `json:"sim"`
+	Verified       bool       `json:"verified"`
+	ProfilePicture string     `json:"profile_picture"`
+	KTP            string     `json:"ktp"`
+	Status         string     `json:"status"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	Version        uint       `json:"version"`
 }
 
 type Passengers struct {
-	ID          string    `json:"id"`
-	Email       string    `json:"email"`
-	Name        string    `json:"name"`
-	DateOfBirth time.Time `json:"date_of_birth"`
-	Age         int       `json:"age"`
+	ID          string     `json:"id"`
+	Email       string     `json:"email"`
+	Name        string     `json:"name"`
+	DateOfBirth time.Time  `json:"date_of_birth"`
+	Age         int        `json:"age"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Reviews struct {
@@ -43,6 +46,7 @@ type Histories struct {
 	PassengerName string    `json:"passenger_name"`
 	DriverName    string    `json:"driver_name"`
 	Amount        int       `json:"amount"`
+	Fare          *int      `json:"fare"`
 	Route         string    `json:"route"`
 	CreatedAt     time.Time `json:"created_at"`
 }
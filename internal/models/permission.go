@@ -0,0 +1,169 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PermDriversRead     = "drivers:read"
+	PermDriversVerify   = "drivers:verify"
+	PermDriversDelete   = "drivers:delete"
+	PermDriversKTP      = "drivers:ktp"
+	PermUsersRead       = "users:read"
+	PermUsersDelete     = "users:delete"
+	PermAccountsRead    = "accounts:read"
+	PermAccountsBlock   = "accounts:block"
+	PermReviewsRead     = "reviews:read"
+	PermRoutesRead      = "routes:read"
+	PermRoutesWrite     = "routes:write"
+	PermReportsRead     = "reports:read"
+	PermAdminsWrite     = "admins:write"
+	PermApiKeysWrite    = "apikeys:write"
+	PermAuditRead       = "audit:read"
+	PermProposalsDecide = "proposals:decide"
+	PermSearch          = "search:read"
+)
+
+const (
+	RoleSuperAdmin = "super-admin"
+	RoleFinance    = "finance"
+	RoleModerator  = "moderator"
+	RoleViewer     = "viewer"
+)
+
+var AllPermissions = []string{
+	PermDriversRead,
+	PermDriversVerify,
+	PermDriversDelete,
+	PermDriversKTP,
+	PermUsersRead,
+	PermUsersDelete,
+	PermAccountsRead,
+	PermAccountsBlock,
+	PermReviewsRead,
+	PermRoutesRead,
+	PermRoutesWrite,
+	PermReportsRead,
+	PermAdminsWrite,
+	PermApiKeysWrite,
+	PermAuditRead,
+	PermProposalsDecide,
+	PermSearch,
+}
+
+// AdminOnlyPermissions cannot be granted to API keys, so a leaked key can
+// never mint new keys or change who administers the dashboard.
+var AdminOnlyPermissions = []string{
+	PermAdminsWrite,
+	PermApiKeysWrite,
+	PermAuditRead,
+	PermProposalsDecide,
+}
+
+// DefaultRoles is the permission set of every built-in role. It is written to
+// the database on startup, so editing this map is how a role is changed.
+var DefaultRoles = map[string][]string{
+	RoleSuperAdmin: AllPermissions,
+	RoleFinance: {
+		PermReportsRead,
+		PermRoutesRead,
+		PermRoutesWrite,
+	},
+	RoleModerator: {
+		PermDriversRead,
+		PermDriversVerify,
+		PermDriversKTP,
+		PermUsersRead,
+		PermAccountsRead,
+		PermAccountsBlock,
+		PermReviewsRead,
+		PermRoutesRead,
+		PermSearch,
+	},
+	RoleViewer: {
+		PermDriversRead,
+		PermUsersRead,
+		PermReviewsRead,
+		PermRoutesRead,
+		PermReportsRead,
+		PermSearch,
+	},
+}
+
+// Scopes is a list of permission names stored as one space separated column.
+type Scopes []string
+
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+func (s *Scopes) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = nil
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", value)
+	}
+
+	return nil
+}
+
+func (s Scopes) Has(permission string) bool {
+	return slices.Contains(s, permission)
+}
+
+// SeedRoles writes DefaultRoles to the database and promotes the admins in
+// SUPER_ADMIN_IDS. It runs on every start and is safe to repeat.
+func SeedRoles(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		perms := make([]Permission, 0, len(AllPermissions))
+		for _, name := range AllPermissions {
+			perms = append(perms, Permission{Name: name})
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&perms).Error; err != nil {
+			return err
+		}
+
+		for name, names := range DefaultRoles {
+			role := Role{Name: name}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&role).Error; err != nil {
+				return err
+			}
+
+			rolePerms := make([]Permission, 0, len(names))
+			for _, p := range names {
+				rolePerms = append(rolePerms, Permission{Name: p})
+			}
+
+			if err := tx.Model(&role).Association("Permissions").Replace(rolePerms); err != nil {
+				return err
+			}
+		}
+
+		// SUPER_ADMIN_IDS bootstraps the first super-admin, who can then hand
+		// out roles through the dashboard.
+		for _, id := range strings.Split(os.Getenv("SUPER_ADMIN_IDS"), ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+
+			if err := tx.Model(&Admin{}).Where("id = ?", id).Update("role_name", RoleSuperAdmin).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ReplicaResolver is the resolver repositories name with dbresolver.Use to
+// send a read-only query to a replica.
+const ReplicaResolver = "read-replica"
+
+// ReplicaConfig lists the read replicas and when they are considered too far
+// behind the primary to serve reads.
+type ReplicaConfig struct {
+	Addrs    []string
+	MaxLag   time.Duration
+	Interval time.Duration
+}
+
+// ReplicaConfigFromEnv reads DB_REPLICAS, a comma separated list of
+// host:port, DB_REPLICA_MAX_LAG (default 10s) and DB_REPLICA_CHECK_INTERVAL
+// (default 5s). Replicas share the credentials and name of the primary.
+func ReplicaConfigFromEnv() (cfg ReplicaConfig, err error) {
+	for _, addr := range strings.Split(os.Getenv("DB_REPLICAS"), ",") {
+		if addr = strings.TrimSpace(addr); addr == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return cfg, fmt.Errorf("DB_REPLICAS: invalid address %q", addr)
+		}
+		cfg.Addrs = append(cfg.Addrs, addr)
+	}
+
+	cfg.MaxLag = 10 * time.Second
+	if v := os.Getenv("DB_REPLICA_MAX_LAG"); v != "" {
+		if cfg.MaxLag, err = time.ParseDuration(v); err != nil || cfg.MaxLag <= 0 {
+			return cfg, fmt.Errorf("DB_REPLICA_MAX_LAG: invalid duration %q", v)
+		}
+	}
+
+	cfg.Interval = 5 * time.Second
+	if v := os.Getenv("DB_REPLICA_CHECK_INTERVAL"); v != "" {
+		if cfg.Interval, err = time.ParseDuration(v); err != nil || cfg.Interval <= 0 {
+			return cfg, fmt.Errorf("DB_REPLICA_CHECK_INTERVAL: invalid duration %q", v)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Replicas is the dbresolver policy of ReplicaResolver. It spreads reads over
+// the replicas that passed their last health check and falls back to the
+// primary when none did.
+type Replicas struct {
+	config  ReplicaConfig
+	dialect string
+	checks  []*gorm.DB
+	healthy []atomic.Bool
+	next    atomic.Uint64
+}
+
+// UseReplicas registers the replicas of cfg on db and checks them once. It
+// also marks the request context on every write, see helper.HasWritten. The
+// returned Replicas has to be Run to keep the health state current.
+func UseReplicas(db *gorm.DB, cfg ReplicaConfig) (*Replicas, error) {
+	r := &Replicas{
+		config:  cfg,
+		dialect: db.Dialector.Name(),
+		healthy: make([]atomic.Bool, len(cfg.Addrs)),
+	}
+
+	if r.dialect == "sqlite" {
+		return nil, errors.New("DB_REPLICAS: sqlite has no replicas")
+	}
+
+	var dialectors []gorm.Dialector
+	for _, addr := range cfg.Addrs {
+		host, port, _ := net.SplitHostPort(addr)
+
+		dialector, err := dialectorFromEnv(host, port)
+		if err != nil {
+			return nil, err
+		}
+		dialectors = append(dialectors, dialector)
+
+		// The health check gets a connection pool of its own, so a busy
+		// replica cannot hold up the check behind queued reads. Building the
+		// same dialector again cannot fail.
+		checkDialector, _ := dialectorFromEnv(host, port)
+		check, err := gorm.Open(checkDialector, &gorm.Config{Logger: db.Logger})
+		if err != nil {
+			return nil, fmt.Errorf("replica %s: %w", addr, err)
+		}
+		r.checks = append(r.checks, check)
+	}
+
+	// The primary is the last pool handed to Resolve, so it can stand in
+	// when every replica is down.
+	primary, err := dialectorFromEnv(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: append(dialectors, primary),
+		Policy:   r,
+	}, ReplicaResolver)); err != nil {
+		return nil, err
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:create").Register("mikronet:mark_written", markWritten),
+		callbacks.Update().After("gorm:update").Register("mikronet:mark_written", markWritten),
+		callbacks.Delete().After("gorm:delete").Register("mikronet:mark_written", markWritten),
+		callbacks.Raw().After("gorm:raw").Register("mikronet:mark_written", markWritten),
+	} {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.check(context.Background())
+
+	return r, nil
+}
+
+func markWritten(tx *gorm.DB) {
+	if tx.Statement.Context != nil {
+		helper.MarkWritten(tx.Statement.Context)
+	}
+}
+
+func (r *Replicas) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	replicas := pools[:len(pools)-1]
+
+	for range replicas {
+		i := int(r.next.Add(1) % uint64(len(replicas)))
+		if r.healthy[i].Load() {
+			return replicas[i]
+		}
+	}
+
+	return pools[len(pools)-1]
+}
+
+// Run checks every replica each interval until c is done.
+func (r *Replicas) Run(c context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+			r.check(c)
+		}
+	}
+}
+
+func (r *Replicas) check(c context.Context) {
+	for i, db := range r.checks {
+		c, cancel := context.WithTimeout(c, r.config.Interval)
+		lag, err := r.lag(c, db)
+		cancel()
+
+		healthy := err == nil && lag <= r.config.MaxLag
+		if r.healthy[i].Swap(healthy) != healthy {
+			if healthy {
+				log.Printf("replica %s is back, lag %s", r.config.Addrs[i], lag)
+			} else if err != nil {
+				log.Printf("replica %s is unhealthy, reading from the primary: %v", r.config.Addrs[i], err)
+			} else {
+				log.Printf("replica %s lags %s behind, reading from the primary", r.config.Addrs[i], lag)
+			}
+		}
+	}
+}
+
+// lag asks the replica how far it is behind the primary. A replica whose
+// replication is stopped reports an error.
+func (r *Replicas) lag(c context.Context, db *gorm.DB) (time.Duration, error) {
+	switch r.dialect {
+	case "postgres":
+		var seconds float64
+		err := db.WithContext(c).Raw(`SELECT CASE
+			WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+		END`).Row().Scan(&seconds)
+		return time.Duration(seconds * float64(time.Second)), err
+	default:
+		// SHOW REPLICA STATUS needs MySQL 8.0.22 or MariaDB 10.5; older
+		// servers only know the SLAVE spelling.
+		lag, err := mysqlLag(c, db, "SHOW REPLICA STATUS", "Seconds_Behind_Source")
+		if err != nil {
+			lag, err = mysqlLag(c, db, "SHOW SLAVE STATUS", "Seconds_Behind_Master")
+		}
+		return lag, err
+	}
+}
+
+func mysqlLag(c context.Context, db *gorm.DB, statement string, column string) (time.Duration, error) {
+	rows, err := db.WithContext(c).Raw(statement).Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		// Not replicating at all, which is the case for the primary.
+		return 0, rows.Err()
+	}
+
+	status := map[string]any{}
+	if err := db.ScanRows(rows, &status); err != nil {
+		return 0, err
+	}
+
+	var seconds sql.NullInt64
+	if err := seconds.Scan(status[column]); err != nil {
+		return 0, err
+	}
+	if !seconds.Valid {
+		return 0, errors.New("replication is not running")
+	}
+
+	return time.Duration(seconds.Int64) * time.Second, nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import "gorm.io/gorm"
+
+// FullTextIndex is a MySQL FULLTEXT index used by the global search. MATCH
+// must name exactly the columns of an index, so each searched text column
+// gets its own. The indexes are created by migration 0007.
+type FullTextIndex struct {
+	Name   string
+	Table  string
+	Column string
+}
+
+var FullTextIndexes = []FullTextIndex{
+	{Name: "ft_driver_details_name", Table: "driver_details", Column: "name"},
+	{Name: "ft_passenger_details_name", Table: "passenger_details", Column: "name"},
+	{Name: "ft_users_email", Table: "users", Column: "email"},
+	{Name: "ft_routes_route_name", Table: "routes", Column: "route_name"},
+	{Name: "ft_reviews_comment", Table: "reviews", Column: "comment"},
+}
+
+// HasFullTextSearch reports whether every search index exists, which is only
+// ever the case on MySQL.
+func HasFullTextSearch(db *gorm.DB) bool {
+	if db.Dialector.Name() != "mysql" {
+		return false
+	}
+
+	for _, index := range FullTextIndexes {
+		if !db.Migrator().HasIndex(index.Table, index.Name) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSON is a raw JSON document stored in a text column.
+type JSON json.RawMessage
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*j = JSON(v)
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case nil:
+		*j = nil
+	default:
+		return fmt.Errorf("cannot scan %T into JSON", value)
+	}
+
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+
+	return j, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type AdminRepo interface {
+	GetPermissions(c context.Context, adminID string) ([]string, error)
+	GetRoles(c context.Context) ([]models.Role, error)
+	SetAdminRole(c context.Context, adminID string, role string) (models.Admin, error)
+}
+
+type AdminRepoImpl struct {
+	db *gorm.DB
+}
+
+func (a *AdminRepoImpl) GetPermissions(c context.Context, adminID string) (res []string, err error) {
+	if err := a.db.WithContext(c).Table("admins as a").
+		Select("rp.permission_name").
+		Joins("JOIN role_permissions rp ON rp.role_name = a.role_name").
+		Where("a.id = ?", adminID).
+		Scan(&res).Error; err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	return res, nil
+}
+
+func (a *AdminRepoImpl) GetRoles(c context.Context) (res []models.Role, err error) {
+	if err := a.db.WithContext(c).Preload("Permissions").Order("name").Find(&res).Error; err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	return res, nil
+}
+
+func (a *AdminRepoImpl) SetAdminRole(c context.Context, adminID string, role string) (res models.Admin, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Role{}, "name = ?", role).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return helper.ErrInvalidInput
+			}
+			return err
+		}
+
+		if err := tx.First(&res, "id = ?", adminID).Error; err != nil {
+			return err
+		}
+		before := res.RoleName
+
+		if err := tx.Model(&res).Update("role_name", role).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "admin.role", "admin", adminID, map[string]string{"role": before}, map[string]string{"role": role})
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
+func NewAdminRepo(db *gorm.DB) AdminRepo {
+	return &AdminRepoImpl{
+		db: db,
+	}
+}
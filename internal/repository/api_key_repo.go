@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepo interface {
+	CreateApiKey(c context.Context, data models.ApiKey) (models.ApiKey, error)
+	GetApiKeys(c context.Context) ([]models.ApiKey, error)
+	GetApiKeyByPrefix(c context.Context, prefix string) (models.ApiKey, error)
+	RevokeApiKey(c context.Context, id string) (models.ApiKey, error)
+	RotateApiKey(c context.Context, id string, prefix string, hash string) (models.ApiKey, error)
+	TouchApiKey(c context.Context, id string, at time.Time) error
+}
+
+type ApiKeyRepoImpl struct {
+	db *gorm.DB
+}
+
+func (a *ApiKeyRepoImpl) CreateApiKey(c context.Context, data models.ApiKey) (res models.ApiKey, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&data).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "api_key.create", "api_key", data.ID, nil, data)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return data, nil
+}
+
+func (a *ApiKeyRepoImpl) GetApiKeys(c context.Context) (res []models.ApiKey, err error) {
+	if err := a.db.WithContext(c).Order("created_at desc").Find(&res).Error; err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	return res, nil
+}
+
+func (a *ApiKeyRepoImpl) GetApiKeyByPrefix(c context.Context, prefix string) (res models.ApiKey, err error) {
+	if err := a.db.WithContext(c).First(&res, "prefix = ?", prefix).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return res, helper.ErrNotFound
+		}
+		return res, helper.ErrDatabase
+	}
+
+	return res, nil
+}
+
+func (a *ApiKeyRepoImpl) RevokeApiKey(c context.Context, id string) (res models.ApiKey, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&res, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if res.RevokedAt != nil {
+			return nil
+		}
+		before := res
+
+		now := time.Now()
+		if err := tx.Model(&res).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		res.RevokedAt = &now
+
+		return writeAudit(c, tx, "api_key.revoke", "api_key", id, before, res)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
+func (a *ApiKeyRepoImpl) RotateApiKey(c context.Context, id string, prefix string, hash string) (res models.ApiKey, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&res, "id = ? AND revoked_at IS NULL", id).Error; err != nil {
+			return err
+		}
+		before := res
+
+		if err := tx.Model(&res).Updates(map[string]any{
+			"prefix":       prefix,
+			"hash":         hash,
+			"last_used_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		res.Prefix, res.Hash, res.LastUsedAt = prefix, hash, nil
+
+		return writeAudit(c, tx, "api_key.rotate", "api_key", id, before, res)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
+func (a *ApiKeyRepoImpl) TouchApiKey(c context.Context, id string, at time.Time) error {
+	if err := a.db.WithContext(c).Model(&models.ApiKey{}).Where("id = ?", id).Update("last_used_at", at).Error; err != nil {
+		return helper.ErrDatabase
+	}
+
+	return nil
+}
+
+func NewApiKeyRepo(db *gorm.DB) ApiKeyRepo {
+	return &ApiKeyRepoImpl{
+		db: db,
+	}
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuditRepo interface {
+	GetAuditLogs(c context.Context, q dto.AuditQuery) ([]models.AuditLog, int64, error)
+}
+
+type AuditRepoImpl struct {
+	db *gorm.DB
+}
+
+func (a *AuditRepoImpl) GetAuditLogs(c context.Context, q dto.AuditQuery) (res []models.AuditLog, total int64, err error) {
+	query := reader(c, a.db).Model(&models.AuditLog{})
+
+	if q.ActorID != "" {
+		query = query.Where("actor_id = ?", q.ActorID)
+	}
+	if q.Action != "" {
+		query = query.Where("action = ?", q.Action)
+	}
+	if q.TargetType != "" {
+		query = query.Where("target_type = ?", q.TargetType)
+	}
+	if q.TargetID != "" {
+		query = query.Where("target_id = ?", q.TargetID)
+	}
+	if !q.From.IsZero() {
+		query = query.Where("created_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		query = query.Where("created_at < ?", q.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return res, total, helper.ErrDatabase
+	}
+
+	if err := query.Order("id desc").Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(&res).Error; err != nil {
+		return res, total, helper.ErrDatabase
+	}
+
+	return res, total, nil
+}
+
+// writeAudit records a mutation inside tx, so the entry is committed or
+// rolled back together with the change it describes. before and after are
+// the state of the target around the change; pass nil for a side that does
+// not exist.
+func writeAudit(c context.Context, tx *gorm.DB, action string, targetType string, targetID string, before any, after any) error {
+	entry, err := auditEntry(c, action, targetType, targetID, before, after)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// auditEntry builds the audit log entry of a mutation made by the actor of c.
+func auditEntry(c context.Context, action string, targetType string, targetID string, before any, after any) (entry models.AuditLog, err error) {
+	actor := helper.ActorFrom(c)
+
+	entry = models.AuditLog{
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         actor.IP,
+	}
+
+	if entry.Before, err = snapshot(before); err != nil {
+		return entry, err
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+func snapshot(v any) (models.JSON, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.JSON(raw), nil
+}
+
+// dbError maps errors returned from inside a transaction to the helper
+// errors the services switch on.
+func dbError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, helper.ErrNotFound):
+		return helper.ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return helper.ErrDuplicateEntry
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return helper.ErrInUse
+	case errors.Is(err, helper.ErrVersionMismatch):
+		return helper.ErrVersionMismatch
+	case errors.Is(err, helper.ErrFareExists):
+		return helper.ErrFareExists
+	case errors.Is(err, helper.ErrFareInEffect):
+		return helper.ErrFareInEffect
+	case errors.Is(err, helper.ErrInvalidInput):
+		return helper.ErrInvalidInput
+	default:
+		return helper.ErrDatabase
+	}
+}
+
+func NewAuditRepo(db *gorm.DB) AuditRepo {
+	return &AuditRepoImpl{
+		db: db,
+	}
+}
@@ -3,242 +3,854 @@ package repository
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
 	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
 	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
-	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
+// DashboardRepo is the data of the dashboard. Mutations of routes and
+// drivers take the version the change is based on, EditAmountRoute in
+// data.Version, and fail with helper.ErrVersionMismatch when the row has moved
+// on since. A version of zero applies the change to whatever is current.
+//
+// The fare of a route is kept as a history of models.RouteFare. Amount of a
+// route read from the repo is the fare in effect now, and ApplyRouteFares
+// writes fares that have taken effect since into routes.amount.
+//
+// DriverEarnings reports on every driver that has not been deleted, or only on
+// driver id when id is not empty, which fails with helper.ErrNotFound if there
+// is no such driver.
 type DashboardRepo interface {
-	GetAllDrivers(c context.Context, verified *bool) ([]models.Drivers, error)
-	GetAllPassengers(c context.Context) ([]models.Passengers, error)
+	GetAllDrivers(c context.Context, q dto.ListQuery) ([]models.Drivers, dto.PageInfo, error)
+	GetAllPassengers(c context.Context, q dto.ListQuery) ([]models.Passengers, dto.PageInfo, error)
 	GetDriverByID(c context.Context, id string) (models.Drivers, error)
 	GetPassengerByID(c context.Context, id string) (models.Passengers, error)
-	GetAllReview(c context.Context) ([]models.Reviews, error)
+	GetAllReview(c context.Context, q dto.ListQuery) ([]models.Reviews, dto.PageInfo, error)
 	GetReviewById(c context.Context, id string) (models.Reviews, error)
-	GetAllTripHistories(c context.Context) ([]models.Histories, error)
+	GetAllTripHistories(c context.Context, q dto.ListQuery) ([]models.Histories, dto.PageInfo, error)
 	EditAmountRoute(c context.Context, data models.Route, id string) (models.Route, error)
+	GetRouteByID(c context.Context, id string) (models.Route, error)
 	BlockAccount(c context.Context, data models.BlockedAccount) (models.BlockedAccount, error)
 	UnblockAccount(c context.Context, id string) (string, error)
 	IsBlocked(c context.Context, id string) (bool, error)
-	GetAllBlcokAccount(c context.Context) ([]models.BlockDriver, error)
-	SetDriverStatusVerified(c context.Context, id string) (string, error)
-	DeleteDriver(c context.Context, id string) (string, error)
-	DeleteUser(c context.Context, id string) (string, error)
+	GetAllBlcokAccount(c context.Context, q dto.ListQuery) ([]models.BlockDriver, dto.PageInfo, error)
+	SetDriverStatusVerified(c context.Context, id string, version uint) (models.Drivers, error)
+	DeleteDriver(c context.Context, id string, version uint) (dto.DeletionReport, error)
+	DeleteUser(c context.Context, id string) (dto.DeletionReport, error)
+	RestoreDriver(c context.Context, id string) (string, error)
+	RestoreUser(c context.Context, id string) (string, error)
 	AddRoute(c context.Context, data models.Route) (models.Route, error)
-	MonthlyReport(c context.Context, month int) (dto.Report, error)
+	Report(c context.Context, w dto.ReportWindow) (dto.Report, error)
+	DriverEarnings(c context.Context, w dto.ReportWindow, id string) ([]dto.DriverEarnings, error)
+	Demand(c context.Context, w dto.ReportWindow) (dto.Demand, error)
 	GetRoutes(c context.Context) ([]models.Route, error)
-	DeleteRoute(c context.Context, id string) (string, error)
+	DeleteRoute(c context.Context, id string, version uint) (string, error)
+	GetRouteFares(c context.Context, id string) ([]models.RouteFare, error)
+	ScheduleRouteFare(c context.Context, id string, data models.RouteFare) (models.RouteFare, error)
+	CancelRouteFare(c context.Context, id string, fareID string) (models.RouteFare, error)
+	ApplyRouteFares(c context.Context, now time.Time) ([]models.Route, error)
 }
 
 type DashboardRepoImpl struct {
 	db *gorm.DB
 }
 
-func (a *DashboardRepoImpl) DeleteRoute(c context.Context, id string) (res string, err error) {
-	if err := a.db.WithContext(c).Delete(&models.Route{}, "id = ?", id).Error; err != nil {
-		return res, helper.ErrDatabase
+// Trips and reviews are joined to their accounts with LEFT JOIN, since purging
+// an account detaches its trips. The missing names read as anonymizedName.
+const (
+	passengerName = "COALESCE(p.name, '" + anonymizedName + "')"
+	driverName    = "COALESCE(d.name, '" + anonymizedName + "')"
+)
+
+var (
+	driverList = listSpec{
+		Fields: map[string]listField{
+			"id":             {Column: "d.id"},
+			"email":          {Column: "u.email"},
+			"name":           {Column: "d.name"},
+			"phone_number":   {Column: "d.phone_number"},
+			"license_number": {Column: "d.license_number"},
+			"verified":       {Column: "d.verified", Kind: kindBool},
+			"status":         {Column: "d.status"},
+			"route_id":       {Column: "d.route_id", Kind: kindNumber, FilterOnly: true},
+			"deleted_at":     {Column: "d.deleted_at", Kind: kindTime, FilterOnly: true},
+		},
+		ID:          "id",
+		DefaultSort: "id",
+		SoftDelete:  "d.deleted_at",
+	}
+
+	passengerList = listSpec{
+		Fields: map[string]listField{
+			"id":            {Column: "p.id"},
+			"email":         {Column: "u.email"},
+			"name":          {Column: "p.name"},
+			"date_of_birth": {Column: "p.date_of_birth", Kind: kindTime},
+			"age":           {Column: "p.age", Kind: kindNumber},
+			"deleted_at":    {Column: "p.deleted_at", Kind: kindTime, FilterOnly: true},
+		},
+		ID:          "id",
+		DefaultSort: "id",
+		SoftDelete:  "p.deleted_at",
+	}
+
+	reviewList = listSpec{
+		Fields: map[string]listField{
+			"id":             {Column: "r.id", Kind: kindNumber},
+			"passenger_name": {Column: passengerName},
+			"driver_name":    {Column: driverName},
+			"star":           {Column: "r.star", Kind: kindNumber},
+			"passenger_id":   {Column: "r.passenger_id", FilterOnly: true},
+			"driver_id":      {Column: "r.driver_id", FilterOnly: true},
+		},
+		ID:          "id",
+		DefaultSort: "id",
+		DefaultDesc: true,
+	}
+
+	historyList = listSpec{
+		Fields: map[string]listField{
+			"id":             {Column: "t.id", Kind: kindNumber},
+			"passenger_name": {Column: passengerName},
+			"driver_name":    {Column: driverName},
+			"amount":         {Column: "t.amount", Kind: kindNumber},
+			"route":          {Column: "COALESCE(r.route_name, '')"},
+			"created_at":     {Column: "t.created_at", Kind: kindTime},
+			"passenger_id":   {Column: "t.passenger_id", FilterOnly: true},
+			"driver_id":      {Column: "t.driver_id", FilterOnly: true},
+			"route_id":       {Column: "t.route_id", Kind: kindNumber, FilterOnly: true},
+		},
+		ID:          "id",
+		DefaultSort: "created_at",
+		DefaultDesc: true,
+	}
+
+	blockedList = listSpec{
+		Fields: map[string]listField{
+			"id":    {Column: "b.user_id"},
+			"email": {Column: "u.email"},
+			"name":  {Column: "d.name"},
+		},
+		ID:          "id",
+		DefaultSort: "id",
+	}
+)
+
+func (a *DashboardRepoImpl) DeleteRoute(c context.Context, id string, version uint) (res string, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before models.Route
+		if err := tx.First(&before, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := checkVersion(before.Version, version); err != nil {
+			return err
+		}
+
+		result := tx.Where("version = ?", before.Version).Delete(&before)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return helper.ErrVersionMismatch
+		}
+
+		return writeAudit(c, tx, "route.delete", "route", id, before, nil)
+	}); err != nil {
+		return res, dbError(err)
 	}
 
 	return "Berhasil menghapus rute!", nil
 }
 
+func (a *DashboardRepoImpl) GetRouteByID(c context.Context, id string) (res models.Route, err error) {
+	if err := routesAt(reader(c, a.db), time.Now()).Where("r.id = ?", id).Take(&res).Error; err != nil {
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
 func (a *DashboardRepoImpl) GetRoutes(c context.Context) (res []models.Route, err error) {
-	if err := a.db.WithContext(c).Find(&res).Error; err != nil {
+	if err := routesAt(reader(c, a.db), time.Now()).Order("r.id").Find(&res).Error; err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	return res, nil
+}
+
+// routesAt selects the routes with the fare in effect at the given time as
+// their amount. A route without fares keeps routes.amount.
+func routesAt(tx *gorm.DB, at time.Time) *gorm.DB {
+	at = fareTime(at)
+
+	return tx.Table("routes as r").
+		Select("r.id, r.route_name, COALESCE(f.amount, r.amount) as amount, r.version").
+		Joins("LEFT JOIN route_fares f ON f.route_id = r.id AND f.effective_from <= ? AND (f.effective_to IS NULL OR f.effective_to > ?)", at, at)
+}
+
+// fareTime is how fare boundaries are stored. SQLite compares times as text,
+// which only orders them right when they are all in the same zone.
+func fareTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+func (a *DashboardRepoImpl) GetRouteFares(c context.Context, id string) (res []models.RouteFare, err error) {
+	if err := reader(c, a.db).First(&models.Route{}, "id = ?", id).Error; err != nil {
+		return res, dbError(err)
+	}
+
+	if err := reader(c, a.db).Where("route_id = ?", id).Order("effective_from").Find(&res).Error; err != nil {
 		return res, helper.ErrDatabase
 	}
 
 	return res, nil
 }
 
-func (a *DashboardRepoImpl) GetAllTripHistories(c context.Context) (res []models.Histories, err error) {
-	if err := a.db.WithContext(c).Table("transactions as t").
-		Select("t.id as id, p.name as passenger_name, d.name as driver_name, t.amount as amount, r.route_name as route, t.created_at").
-		Joins("JOIN passenger_details p on p.id = t.passenger_id").
-		Joins("JOIN driver_details d on d.id = t.driver_id").
-		Joins("JOIN routes r on r.id = d.route_id").
-		Scan(&res).Error; err != nil {
-		return nil, helper.ErrDatabase
+func (a *DashboardRepoImpl) ScheduleRouteFare(c context.Context, id string, data models.RouteFare) (res models.RouteFare, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var route models.Route
+		if err := tx.First(&route, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if res, err = insertFare(c, tx, route.ID, data.Amount, data.EffectiveFrom); err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "route.fare_schedule", "route", id, nil, res)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
+// CancelRouteFare removes a fare that has not taken effect yet. The fare
+// before it runs on in its place.
+func (a *DashboardRepoImpl) CancelRouteFare(c context.Context, id string, fareID string) (res models.RouteFare, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&res, "id = ? AND route_id = ?", fareID, id).Error; err != nil {
+			return err
+		}
+
+		if !res.EffectiveFrom.After(time.Now()) {
+			return helper.ErrFareInEffect
+		}
+
+		var prev models.RouteFare
+		if err := tx.Where("route_id = ? AND effective_from < ?", res.RouteID, res.EffectiveFrom).Order("effective_from DESC").Limit(1).Find(&prev).Error; err != nil {
+			return err
+		}
+		if prev.ID != 0 {
+			if err := tx.Model(&prev).Update("effective_to", res.EffectiveTo).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Delete(&res).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "route.fare_cancel", "route", id, res, nil)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
+// insertFare adds a fare of the route from the given time on. The fare in
+// effect at that time ends where the new one starts, and the new one ends
+// where the next one starts.
+func insertFare(c context.Context, tx *gorm.DB, routeID uint, amount int, from time.Time) (res models.RouteFare, err error) {
+	from = fareTime(from)
+
+	var prev, next models.RouteFare
+	if err := tx.Where("route_id = ? AND effective_from <= ?", routeID, from).Order("effective_from DESC").Limit(1).Find(&prev).Error; err != nil {
+		return res, err
+	}
+	if prev.ID != 0 && prev.EffectiveFrom.Equal(from) {
+		return res, helper.ErrFareExists
+	}
+
+	if err := tx.Where("route_id = ? AND effective_from > ?", routeID, from).Order("effective_from").Limit(1).Find(&next).Error; err != nil {
+		return res, err
+	}
+
+	res = models.RouteFare{
+		RouteID:       routeID,
+		Amount:        amount,
+		EffectiveFrom: from,
+		CreatedBy:     helper.ActorFrom(c).ID,
+	}
+	if next.ID != 0 {
+		res.EffectiveTo = &next.EffectiveFrom
+	}
+
+	if prev.ID != 0 {
+		if err := tx.Model(&prev).Update("effective_to", from).Error; err != nil {
+			return res, err
+		}
+	}
+
+	if err := tx.Create(&res).Error; err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// ApplyRouteFares writes the fare in effect at now into routes.amount, which
+// is what the services that charge trips read, and returns the routes that
+// changed.
+func (a *DashboardRepoImpl) ApplyRouteFares(c context.Context, now time.Time) (res []models.Route, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var due []models.Route
+		if err := routesAt(tx, now).Where("f.id IS NOT NULL AND f.amount <> COALESCE(r.amount, -1)").Find(&due).Error; err != nil {
+			return err
+		}
+
+		for _, route := range due {
+			var before models.Route
+			if err := tx.First(&before, "id = ?", route.ID).Error; err != nil {
+				return err
+			}
+
+			id := strconv.FormatUint(uint64(route.ID), 10)
+			if err := bumpVersion(tx, &models.Route{}, id, before.Version, map[string]any{"amount": route.Amount}); err != nil {
+				return err
+			}
+
+			var after models.Route
+			if err := tx.First(&after, "id = ?", route.ID).Error; err != nil {
+				return err
+			}
+
+			if err := writeAudit(c, tx, "route.fare_apply", "route", id, before, after); err != nil {
+				return err
+			}
+			res = append(res, after)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, dbError(err)
 	}
 
 	return res, nil
 }
 
+func (a *DashboardRepoImpl) GetAllTripHistories(c context.Context, q dto.ListQuery) (res []models.Histories, page dto.PageInfo, err error) {
+	query, plan, err := applyList(reader(c, a.db).Table("transactions as t").
+		Select("t.id as id, "+passengerName+" as passenger_name, "+driverName+" as driver_name, t.amount as amount, t.fare as fare, COALESCE(r.route_name, '') as route, t.created_at").
+		Joins("LEFT JOIN passenger_details p on p.id = t.passenger_id").
+		Joins("LEFT JOIN driver_details d on d.id = t.driver_id").
+		Joins("LEFT JOIN routes r on r.id = t.route_id"), historyList, q)
+	if err != nil {
+		return nil, page, err
+	}
+
+	if err := query.Scan(&res).Error; err != nil {
+		return nil, page, helper.ErrDatabase
+	}
+
+	res, page, err = paginate(res, plan)
+	if err != nil {
+		return nil, page, helper.ErrInternal
+	}
+
+	return res, page, nil
+}
+
 func (a *DashboardRepoImpl) EditAmountRoute(c context.Context, data models.Route, id string) (res models.Route, err error) {
-	if err := a.db.WithContext(c).Model(&res).Where("id = ?", id).Update("amount", data.Amount).Error; err != nil {
-		return res, helper.ErrDatabase
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before models.Route
+		if err := tx.First(&before, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := checkVersion(before.Version, data.Version); err != nil {
+			return err
+		}
+
+		if err := bumpVersion(tx, &models.Route{}, id, before.Version, map[string]any{"amount": data.Amount}); err != nil {
+			return err
+		}
+
+		// A fare set by hand applies right away, up to the next scheduled one.
+		if _, err := insertFare(c, tx, before.ID, data.Amount, time.Now()); err != nil {
+			return err
+		}
+
+		if err := tx.First(&res, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "route.update", "route", id, before, res)
+	}); err != nil {
+		return res, dbError(err)
 	}
 
-	return data, nil
+	return res, nil
 }
 
-func (a *DashboardRepoImpl) MonthlyReport(c context.Context, month int) (res dto.Report, err error) {
-	monthAgo := time.Now().AddDate(0, month*-1, 0)
-	trips := []dto.RoutesReport{}
-	common := dto.CommonReport{}
+func (a *DashboardRepoImpl) Report(c context.Context, w dto.ReportWindow) (res dto.Report, err error) {
+	var accounts dto.CommonReport
 
+	// Plain scalar subqueries instead of a CTE, so the same statement runs on
+	// every supported database.
 	sql := `
-		WITH total_passenger AS (
-    SELECT count(id) as total_passenger from passenger_details
-    ), 
-    total_driver AS (
-        SELECT count(id) as total_driver from driver_details
-    )
-		SELECT COUNT(id) as total_trip, 
-		SUM(amount) as total_revenue,
-		(SELECT total_passenger FROM total_passenger) as total_passenger,
-		(SELECT total_driver FROM total_driver) as total_driver FROM transactions;
+		SELECT
+			(SELECT COUNT(id) FROM passenger_details WHERE deleted_at IS NULL) AS total_passenger,
+			(SELECT COUNT(id) FROM driver_details WHERE deleted_at IS NULL) AS total_driver
 	`
 
-	if err := a.db.WithContext(c).Raw(sql).Scan(&common).Error; err != nil {
+	if err := reader(c, a.db).Raw(sql).Scan(&accounts).Error; err != nil {
 		return res, helper.ErrDatabase
 	}
 
-	if err := a.db.WithContext(c).Table("routes as r").
-		Select("CONCAT('Rute ', r.id) as route, count(r.id) as total, sum(t.amount) as revenue").
-		Joins("JOIN driver_details d on d.route_id = r.id").
-		Joins("JOIN transactions t ON t.driver_id = d.id").
-		Where("t.created_at >= ?", monthAgo).
-		Group("r.id").
-		Order("r.id").
-		Scan(&trips).Error; err != nil {
+	// SQLite compares times as text, so the bounds are passed in UTC like
+	// the timestamps it stores.
+	rows, err := reader(c, a.db).Table("transactions as t").
+		Select("t.route_id, t.driver_id, t.passenger_id, COUNT(*) AS trips, SUM(t.amount) AS amount, SUM(t.fare) AS fare, "+hourOf(a.db, "t.created_at")+" AS hour").
+		Where("t.created_at >= ? AND t.created_at < ?", w.From.UTC(), w.To.UTC()).
+		Group(hourOf(a.db, "t.created_at")).Group("t.route_id").Group("t.driver_id").Group("t.passenger_id").
+		Rows()
+	if err != nil {
 		return res, helper.ErrDatabase
 	}
+	defer rows.Close()
 
-	return dto.Report{
-		Common: common,
-		Trips:  trips,
-	}, nil
+	builder := newReportBuilder(w)
+	if err := addHours(a.db, rows, w, builder.add); err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	return builder.report(accounts), nil
 }
 
-func (a *DashboardRepoImpl) AddRoute(c context.Context, data models.Route) (res models.Route, err error) {
-	if err := a.db.WithContext(c).Create(&data).Error; err != nil {
+func (a *DashboardRepoImpl) DriverEarnings(c context.Context, w dto.ReportWindow, id string) (res []dto.DriverEarnings, err error) {
+	var drivers []models.DriverDetails
+
+	query := reader(c, a.db).Select("id, name")
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if err := query.Find(&drivers).Error; err != nil {
 		return res, helper.ErrDatabase
 	}
 
-	return data, nil
-}
+	if id != "" && len(drivers) == 0 {
+		return res, helper.ErrNotFound
+	}
+
+	builder := newEarningsBuilder(w, drivers)
+
+	trips := reader(c, a.db).Table("transactions").
+		Select("driver_id, COUNT(*) AS trips, SUM(amount) AS amount, "+hourOf(a.db, "created_at")+" AS hour").
+		Where("created_at >= ? AND created_at < ?", w.From.UTC(), w.To.UTC()).
+		Group(hourOf(a.db, "created_at")).Group("driver_id")
+	reviews := reader(c, a.db).Table("reviews").
+		Select("driver_id, COUNT(*) AS reviews, SUM(star) AS star").
+		Where("created_at >= ? AND created_at < ?", w.From.UTC(), w.To.UTC()).
+		Group("driver_id")
+	if id != "" {
+		trips = trips.Where("driver_id = ?", id)
+		reviews = reviews.Where("driver_id = ?", id)
+	}
 
-func (a *DashboardRepoImpl) DeleteDriver(c context.Context, id string) (res string, err error) {
-	if err := a.db.WithContext(c).Delete(&models.DriverDetails{}, "id = ?", id).Error; err != nil {
+	rows, err := trips.Rows()
+	if err != nil {
 		return res, helper.ErrDatabase
 	}
+	defer rows.Close()
 
-	return "Berhasil menghapus driver", nil
-}
+	if err := addHours(a.db, rows, w, builder.addTrip); err != nil {
+		return res, helper.ErrDatabase
+	}
 
-func (a *DashboardRepoImpl) DeleteUser(c context.Context, id string) (res string, err error) {
-	if err := a.db.WithContext(c).Delete(&models.PassengerDetails{}, "id = ?", id).Error; err != nil {
+	var stars []earningsReview
+	if err := reviews.Scan(&stars).Error; err != nil {
 		return res, helper.ErrDatabase
 	}
+	for _, review := range stars {
+		builder.addReview(review)
+	}
 
-	return "Berhasil menghapus passenger", nil
+	return builder.earnings(), nil
 }
 
-func (a *DashboardRepoImpl) SetDriverStatusVerified(c context.Context, id string) (res string, err error) {
-	if err := a.db.WithContext(c).Model(&models.DriverDetails{}).Where("id = ?", id).Update("verified", true).Error; err != nil {
+func (a *DashboardRepoImpl) Demand(c context.Context, w dto.ReportWindow) (res dto.Demand, err error) {
+	rows, err := reader(c, a.db).Table("transactions").
+		Select("route_id, COUNT(*) AS trips, SUM(amount) AS amount, "+hourOf(a.db, "created_at")+" AS hour").
+		Where("created_at >= ? AND created_at < ?", w.From.UTC(), w.To.UTC()).
+		Group(hourOf(a.db, "created_at")).Group("route_id").
+		Rows()
+	if err != nil {
+		return res, helper.ErrDatabase
+	}
+	defer rows.Close()
+
+	builder := newDemandBuilder(w)
+	if err := addHours(a.db, rows, w, builder.add); err != nil {
 		return res, helper.ErrDatabase
 	}
 
-	return "Berhasil memverifikasi driver", nil
+	return builder.demand(), nil
 }
 
-func (a *DashboardRepoImpl) GetAllReview(c context.Context) (res []models.Reviews, err error) {
-	if err := a.db.WithContext(c).Table("reviews").
-		Select("reviews.id, p.name AS passenger_name, d.name AS driver_name, reviews.comment AS comment, reviews.star AS star").
-		Joins("JOIN passenger_details p ON reviews.passenger_id = p.id").
-		Joins("JOIN driver_details d ON reviews.driver_id = d.id").
-		Scan(&res).Error; err != nil {
-		return res, helper.ErrDatabase
+func (a *DashboardRepoImpl) AddRoute(c context.Context, data models.Route) (res models.Route, err error) {
+	data.Version = 1
+
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&data).Error; err != nil {
+			return err
+		}
+
+		if _, err := insertFare(c, tx, data.ID, data.Amount, time.Now()); err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "route.create", "route", strconv.FormatUint(uint64(data.ID), 10), nil, data)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return data, nil
+}
+
+// DeleteDriver soft deletes the driver together with their users row, so the
+// account can be restored until the purge job removes it for good.
+func (a *DashboardRepoImpl) DeleteDriver(c context.Context, id string, version uint) (res dto.DeletionReport, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before models.DriverDetails
+		if err := tx.First(&before, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := checkVersion(before.Version, version); err != nil {
+			return err
+		}
+
+		if err := bumpVersion(tx, &models.DriverDetails{}, id, before.Version, nil); err != nil {
+			return err
+		}
+
+		if res, err = deleteAccount(tx, &before, id); err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "driver.delete", "driver", id, before, res)
+	}); err != nil {
+		return res, dbError(err)
 	}
 
 	return res, nil
 }
 
-func (a *DashboardRepoImpl) GetReviewById(c context.Context, id string) (res models.Reviews, err error) {
-	if err := a.db.WithContext(c).Table("reviews").
-		Select("reviews.id, p.name AS passenger_name, d.name AS driver_name, reviews.comment AS comment, reviews.star AS star").
-		Joins("JOIN passenger_details p ON reviews.passenger_id = p.id").
-		Joins("JOIN driver_details d ON reviews.driver_id = d.id").
-		Where("reviews.id = ?", id).
-		Scan(&res).Error; err != nil {
-		return res, helper.ErrDatabase
+// DeleteUser soft deletes the passenger together with their users row.
+func (a *DashboardRepoImpl) DeleteUser(c context.Context, id string) (res dto.DeletionReport, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before models.PassengerDetails
+		if err := tx.First(&before, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if res, err = deleteAccount(tx, &before, id); err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "passenger.delete", "passenger", id, before, res)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
+// deleteAccount soft deletes details and the users row of id and removes
+// every record that could still be used to get into the account: the password
+// hash is blanked and pending password resets are dropped. A restored account
+// has to reset its password before it can log in again. Block entries stay,
+// so a restored account is still blocked; the purge job removes them.
+func deleteAccount(tx *gorm.DB, details any, id string) (res dto.DeletionReport, err error) {
+	res.ID = id
+
+	result := tx.Delete(details)
+	if result.Error != nil {
+		return res, result.Error
+	}
+	res.Details = result.RowsAffected
+
+	result = tx.Model(&models.User{}).Where("id = ?", id).Update("password", "")
+	if result.Error != nil {
+		return res, result.Error
+	}
+
+	result = tx.Delete(&models.User{}, "id = ?", id)
+	if result.Error != nil {
+		return res, result.Error
+	}
+	res.Users = result.RowsAffected
+
+	result = tx.Where("user_id = ?", id).Delete(&models.ResetPassword{})
+	if result.Error != nil {
+		return res, result.Error
 	}
+	res.ResetPasswords = result.RowsAffected
 
 	return res, nil
 }
 
-func (a *DashboardRepoImpl) GetAllDrivers(c context.Context, verified *bool) (res []models.Drivers, err error) {
-	if verified == nil {
-		if err := a.db.WithContext(c).Table("driver_details as d").
-			Select("d.id as id, u.email, d.name, d.phone_number, d.license_number, d.sim, d.verified, d.profile_picture, d.ktp, d.status as status").
-			Joins("JOIN users u ON u.id = d.id").
-			Scan(&res).Error; err != nil {
-			return res, helper.ErrDatabase
+func (a *DashboardRepoImpl) RestoreDriver(c context.Context, id string) (res string, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before, after models.DriverDetails
+		if err := tx.Unscoped().First(&before, "id = ? AND deleted_at IS NOT NULL AND purged_at IS NULL", id).Error; err != nil {
+			return err
 		}
-	} else {
-		if err := a.db.WithContext(c).Table("driver_details").
-			Select("driver_details.id as id, users.email, driver_details.name, driver_details.phone_number, driver_details.license_number, driver_details.sim, driver_details.verified, driver_details.profile_picture, driver_details.status as status").
-			Joins("JOIN users ON users.id = driver_details.id").
-			Where("verified = ?", verified).
-			Scan(&res).Error; err != nil {
-			return res, helper.ErrDatabase
+
+		if err := restoreAccount(tx, &models.DriverDetails{}, id); err != nil {
+			return err
+		}
+
+		if err := bumpVersion(tx, &models.DriverDetails{}, id, before.Version, nil); err != nil {
+			return err
+		}
+
+		if err := tx.First(&after, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "driver.restore", "driver", id, before, after)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return "Berhasil memulihkan driver, kata sandi harus diatur ulang", nil
+}
+
+func (a *DashboardRepoImpl) RestoreUser(c context.Context, id string) (res string, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before, after models.PassengerDetails
+		if err := tx.Unscoped().First(&before, "id = ? AND deleted_at IS NOT NULL AND purged_at IS NULL", id).Error; err != nil {
+			return err
+		}
+
+		if err := restoreAccount(tx, &models.PassengerDetails{}, id); err != nil {
+			return err
+		}
+
+		if err := tx.First(&after, "id = ?", id).Error; err != nil {
+			return err
 		}
+
+		return writeAudit(c, tx, "passenger.restore", "passenger", id, before, after)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return "Berhasil memulihkan passenger, kata sandi harus diatur ulang", nil
+}
+
+// checkVersion refuses a change based on another version than current.
+func checkVersion(current uint, expected uint) error {
+	if expected != 0 && expected != current {
+		return helper.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// bumpVersion applies updates to row id of model and increments its version,
+// as long as the version is still the one read earlier in the transaction.
+func bumpVersion(tx *gorm.DB, model any, id string, version uint, updates map[string]any) error {
+	if updates == nil {
+		updates = map[string]any{}
+	}
+	updates["version"] = gorm.Expr("version + 1")
+
+	result := tx.Model(model).Where("id = ? AND version = ?", id, version).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return helper.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// restoreAccount clears deleted_at on the details row in model and on the
+// users row it belongs to.
+func restoreAccount(tx *gorm.DB, model any, id string) error {
+	if err := tx.Unscoped().Model(model).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Model(&models.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (a *DashboardRepoImpl) SetDriverStatusVerified(c context.Context, id string, version uint) (res models.Drivers, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before, after models.DriverDetails
+		if err := tx.First(&before, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := checkVersion(before.Version, version); err != nil {
+			return err
+		}
+
+		if err := bumpVersion(tx, &models.DriverDetails{}, id, before.Version, map[string]any{"verified": true}); err != nil {
+			return err
+		}
+
+		if err := tx.First(&after, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := writeAudit(c, tx, "driver.verify", "driver", id, before, after); err != nil {
+			return err
+		}
+
+		return driverByID(tx, id).Take(&res).Error
+	}); err != nil {
+		return res, dbError(err)
 	}
 
 	return res, nil
 }
 
-func (a *DashboardRepoImpl) GetAllPassengers(c context.Context) (res []models.Passengers, err error) {
-	if err := a.db.WithContext(c).Table("passenger_details").
-		Select("passenger_details.id as id, users.email, passenger_details.name, passenger_details.date_of_birth, passenger_details.age").
-		Joins("JOIN users ON users.id = passenger_details.id").
-		Scan(&res).Error; err != nil {
-		return res, helper.ErrDatabase
+func (a *DashboardRepoImpl) GetAllReview(c context.Context, q dto.ListQuery) (res []models.Reviews, page dto.PageInfo, err error) {
+	query, plan, err := applyList(reader(c, a.db).Table("reviews as r").
+		Select("r.id, "+passengerName+" AS passenger_name, "+driverName+" AS driver_name, r.comment AS comment, r.star AS star").
+		Joins("LEFT JOIN passenger_details p ON r.passenger_id = p.id").
+		Joins("LEFT JOIN driver_details d ON r.driver_id = d.id"), reviewList, q)
+	if err != nil {
+		return res, page, err
+	}
+
+	if err := query.Scan(&res).Error; err != nil {
+		return res, page, helper.ErrDatabase
+	}
+
+	res, page, err = paginate(res, plan)
+	if err != nil {
+		return res, page, helper.ErrInternal
+	}
+
+	return res, page, nil
+}
+
+func (a *DashboardRepoImpl) GetReviewById(c context.Context, id string) (res models.Reviews, err error) {
+	if err := reader(c, a.db).Table("reviews").
+		Select("reviews.id, "+passengerName+" AS passenger_name, "+driverName+" AS driver_name, reviews.comment AS comment, reviews.star AS star").
+		Joins("LEFT JOIN passenger_details p ON reviews.passenger_id = p.id").
+		Joins("LEFT JOIN driver_details d ON reviews.driver_id = d.id").
+		Where("reviews.id = ?", id).
+		Take(&res).Error; err != nil {
+		return res, dbError(err)
 	}
 
 	return res, nil
 }
 
+func (a *DashboardRepoImpl) GetAllDrivers(c context.Context, q dto.ListQuery) (res []models.Drivers, page dto.PageInfo, err error) {
+	query, plan, err := applyList(reader(c, a.db).Table("driver_details as d").
+		Select("d.id as id, u.email, d.name, d.phone_number, d.license_number, d.sim, d.verified, d.profile_picture, d.ktp, d.status as status, d.deleted_at, d.version").
+		Joins("JOIN users u ON u.id = d.id"), driverList, q)
+	if err != nil {
+		return res, page, err
+	}
+
+	if err := query.Scan(&res).Error; err != nil {
+		return res, page, helper.ErrDatabase
+	}
+
+	res, page, err = paginate(res, plan)
+	if err != nil {
+		return res, page, helper.ErrInternal
+	}
+
+	return res, page, nil
+}
+
+func (a *DashboardRepoImpl) GetAllPassengers(c context.Context, q dto.ListQuery) (res []models.Passengers, page dto.PageInfo, err error) {
+	query, plan, err := applyList(reader(c, a.db).Table("passenger_details as p").
+		Select("p.id as id, u.email, p.name, p.date_of_birth, p.age, p.deleted_at").
+		Joins("JOIN users u ON u.id = p.id"), passengerList, q)
+	if err != nil {
+		return res, page, err
+	}
+
+	if err := query.Scan(&res).Error; err != nil {
+		return res, page, helper.ErrDatabase
+	}
+
+	res, page, err = paginate(res, plan)
+	if err != nil {
+		return res, page, helper.ErrInternal
+	}
+
+	return res, page, nil
+}
+
 func (a *DashboardRepoImpl) GetDriverByID(c context.Context, id string) (res models.Drivers, err error) {
-	if err := a.db.WithContext(c).Table("driver_details as d").
-		Select("d.id as id, u.email, d.name, d.phone_number, d.license_number, d.sim, d.verified, d.profile_picture, d.ktp").
-		Joins("JOIN users u ON u.id = d.id").
-		Scan(&res).Error; err != nil {
-		return res, helper.ErrDatabase
+	if err := driverByID(reader(c, a.db), id).Take(&res).Error; err != nil {
+		return res, dbError(err)
 	}
 
 	return res, nil
 }
 
+func driverByID(tx *gorm.DB, id string) *gorm.DB {
+	return tx.Table("driver_details as d").
+		Select("d.id as id, u.email, d.name, d.phone_number, d.license_number, d.sim, d.verified, d.profile_picture, d.ktp, d.version").
+		Joins("JOIN users u ON u.id = d.id").
+		Where("d.id = ? AND d.deleted_at IS NULL", id)
+}
+
 func (a *DashboardRepoImpl) GetPassengerByID(c context.Context, id string) (res models.Passengers, err error) {
-	if err := a.db.WithContext(c).Table("passenger_details").
-		Select("passenger_details.id as id, users.email, passenger_details.name").
-		Joins("JOIN users ON users.id = passenger_details.id").
-		Scan(&res).Error; err != nil {
-		return res, helper.ErrDatabase
+	if err := reader(c, a.db).Table("passenger_details as p").
+		Select("p.id as id, u.email, p.name").
+		Joins("JOIN users u ON u.id = p.id").
+		Where("p.id = ? AND p.deleted_at IS NULL", id).
+		Take(&res).Error; err != nil {
+		return res, dbError(err)
 	}
 
 	return res, nil
 }
 
-func (a *DashboardRepoImpl) GetAllBlcokAccount(c context.Context) (res []models.BlockDriver, err error) {
-	if err := a.db.WithContext(c).Table("blocked_accounts").
-		Select("user_id as id, users.email as email, driver_details.name as name").
-		Joins("JOIN users ON users.id = user_id").
-		Joins("JOIN driver_details ON driver_details.id = user_id").Scan(&res).Error; err != nil {
-		return res, helper.ErrDatabase
+func (a *DashboardRepoImpl) GetAllBlcokAccount(c context.Context, q dto.ListQuery) (res []models.BlockDriver, page dto.PageInfo, err error) {
+	query, plan, err := applyList(reader(c, a.db).Table("blocked_accounts as b").
+		Select("b.user_id as id, u.email as email, d.name as name").
+		Joins("JOIN users u ON u.id = b.user_id").
+		Joins("JOIN driver_details d ON d.id = b.user_id"), blockedList, q)
+	if err != nil {
+		return res, page, err
 	}
 
-	return res, nil
+	if err := query.Scan(&res).Error; err != nil {
+		return res, page, helper.ErrDatabase
+	}
+
+	res, page, err = paginate(res, plan)
+	if err != nil {
+		return res, page, helper.ErrInternal
+	}
+
+	return res, page, nil
 }
 
 func (a *DashboardRepoImpl) IsBlocked(c context.Context, id string) (bool, error) {
 	var res models.BlockedAccount
-	if err := a.db.WithContext(c).First(&res, "account_id = ?", id).Error; err != nil {
+	if err := a.db.WithContext(c).First(&res, "user_id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return false, nil
 		}
@@ -249,26 +861,40 @@ func (a *DashboardRepoImpl) IsBlocked(c context.Context, id string) (bool, error
 }
 
 func (a *DashboardRepoImpl) UnblockAccount(c context.Context, id string) (res string, err error) {
-	if err := a.db.WithContext(c).Delete(&models.BlockedAccount{}, "user_id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return res, helper.ErrNotFound
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before models.BlockedAccount
+		if err := tx.First(&before, "user_id = ?", id).Error; err != nil {
+			return err
 		}
-		return res, helper.ErrDatabase
+
+		if err := tx.Delete(&before).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "account.unblock", "account", id, before, nil)
+	}); err != nil {
+		return res, dbError(err)
 	}
 
 	return "Berhasil membuka blokir akun", nil
 }
 
 func (a *DashboardRepoImpl) BlockAccount(c context.Context, data models.BlockedAccount) (res models.BlockedAccount, err error) {
-	if err := a.db.WithContext(c).Create(&data).Error; err != nil {
-		var mysqlErr *mysql.MySQLError
-		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
-			return res, helper.ErrDuplicateEntry
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.User{}, "id = ?", data.UserID).Error; err != nil {
+			return err
 		}
-		return res, helper.ErrDatabase
+
+		if err := tx.Create(&data).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "account.block", "account", data.UserID, nil, data)
+	}); err != nil {
+		return res, dbError(err)
 	}
 
-	return res, nil
+	return data, nil
 }
 
 func NewDashboardRepo(db *gorm.DB) DashboardRepo {
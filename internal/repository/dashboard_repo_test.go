@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/migrations"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository/repotest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestMemoryDashboardRepo(t *testing.T) {
+	err := repotest.TestDashboardRepo(func(data repository.MemoryData) (repository.DashboardRepo, error) {
+		return repository.NewMemoryDashboardRepo(data), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDashboardRepoOnSQLite(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DB_DRIVER", "sqlite")
+
+	// Every check gets a database of its own.
+	n := 0
+	err := repotest.TestDashboardRepo(func(data repository.MemoryData) (repository.DashboardRepo, error) {
+		n++
+		t.Setenv("DB_NAME", filepath.Join(dir, fmt.Sprintf("check%d.db", n)))
+
+		db, err := seededDB(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return repository.NewDashboardRepo(db), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransactionSnapshotOnSQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_NAME", filepath.Join(t.TempDir(), "snapshot.db"))
+
+	db, err := seededDB(repotest.Fixture())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	ago := now.AddDate(0, 0, -3)
+	route1, route3 := uint(1), uint(3)
+	fare := 9000
+
+	tests := []struct {
+		name      string
+		trip      models.Transaction
+		wantRoute uint
+		wantFare  int
+	}{
+		{"the route of the driver and the fare now", models.Transaction{PassengerID: "p1", DriverID: "d2", Amount: 7000, CreatedAt: &now}, 2, 7000},
+		{"the fare when the trip was made", models.Transaction{PassengerID: "p1", DriverID: "d2", Amount: 6000, CreatedAt: &ago}, 2, 6000},
+		{"the route amount without fares", models.Transaction{PassengerID: "p1", DriverID: "d2", RouteID: &route3, Amount: 4000, CreatedAt: &now}, 3, 4000},
+		{"what the writer filled in", models.Transaction{PassengerID: "p1", DriverID: "d2", RouteID: &route1, Fare: &fare, Amount: 9000, CreatedAt: &now}, 1, 9000},
+	}
+
+	for _, tt := range tests {
+		if err := db.Omit(clause.Associations).Create(&tt.trip).Error; err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var got models.Transaction
+		if err := db.First(&got, tt.trip.ID).Error; err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got.RouteID == nil || *got.RouteID != tt.wantRoute || got.Fare == nil || *got.Fare != tt.wantFare {
+			t.Errorf("%s: got route %v and fare %v, want %d and %d", tt.name, got.RouteID, got.Fare, tt.wantRoute, tt.wantFare)
+		}
+	}
+}
+
+// seededDB migrates the database DB_NAME names and seeds it with data.
+func seededDB(data repository.MemoryData) (*gorm.DB, error) {
+	db := models.DatabaseInit()
+	m, err := migrations.New(db)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		return nil, err
+	}
+	if err := repotest.Seed(db, data); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
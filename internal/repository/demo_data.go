@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+)
+
+// DemoData is the sample content of the demo mode: a few routes with their
+// drivers, passengers and a month of trips and reviews. Trips are spread
+// backwards from now so the reports always have something to show.
+func DemoData() MemoryData {
+	now := time.Now().Truncate(time.Minute)
+
+	data := MemoryData{
+		Routes: []models.Route{
+			{ID: 1, RouteName: "Terminal Malalayang - Pusat Kota", Amount: 5000},
+			{ID: 2, RouteName: "Pusat Kota - Paal Dua", Amount: 5000},
+			{ID: 3, RouteName: "Terminal Karombasan - Unsrat", Amount: 6000},
+		},
+	}
+
+	// Rute 3 went up two weeks ago and Rute 1 goes up next month.
+	since := now.AddDate(-1, 0, 0).UTC()
+	raised := now.AddDate(0, 0, -14).UTC()
+	nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	data.RouteFares = []models.RouteFare{
+		{ID: 1, RouteID: 1, Amount: 5000, EffectiveFrom: since, EffectiveTo: &nextMonth, CreatedBy: "demo"},
+		{ID: 2, RouteID: 1, Amount: 6000, EffectiveFrom: nextMonth, CreatedBy: "demo"},
+		{ID: 3, RouteID: 2, Amount: 5000, EffectiveFrom: since, CreatedBy: "demo"},
+		{ID: 4, RouteID: 3, Amount: 5000, EffectiveFrom: since, EffectiveTo: &raised, CreatedBy: "demo"},
+		{ID: 5, RouteID: 3, Amount: 6000, EffectiveFrom: raised, CreatedBy: "demo"},
+	}
+
+	drivers := []string{"Andi", "Budi", "Citra", "Dimas", "Eka", "Fajar"}
+	for i, name := range drivers {
+		id := fmt.Sprintf("demo-driver-%d", i+1)
+		route := uint(i%3 + 1)
+
+		data.Users = append(data.Users, models.User{ID: id, Email: fmt.Sprintf("driver%d@demo.mikronet", i+1), Role: "driver"})
+		data.Drivers = append(data.Drivers, models.DriverDetails{
+			ID:            id,
+			Name:          name,
+			PhoneNumber:   fmt.Sprintf("0812000000%02d", i+1),
+			RouteID:       &route,
+			LicenseNumber: fmt.Sprintf("DB %04d XY", 1000+i),
+			Status:        "off",
+			Verified:      i < 4,
+		})
+	}
+
+	passengers := []string{"Gita", "Hendra", "Indah", "Joko", "Kirana", "Lukas", "Maya", "Nanda"}
+	for i, name := range passengers {
+		id := fmt.Sprintf("demo-passenger-%d", i+1)
+		age := 18 + i*4
+
+		data.Users = append(data.Users, models.User{ID: id, Email: fmt.Sprintf("passenger%d@demo.mikronet", i+1), Role: "user"})
+		data.Passengers = append(data.Passengers, models.PassengerDetails{
+			ID:          id,
+			Name:        name,
+			DateOfBirth: time.Date(now.Year()-age, time.Month(i%12+1), 10, 0, 0, 0, 0, time.Local),
+			Age:         age,
+		})
+	}
+
+	comments := []string{"Ramah dan tepat waktu", "Mobil bersih", "Agak ngebut", "Sopir sabar", "Perjalanan nyaman"}
+	for i := 0; i < 60; i++ {
+		driver := data.Drivers[i%len(data.Drivers)]
+		passenger := data.Passengers[i%len(data.Passengers)]
+		created := now.Add(-time.Duration(i*11) * time.Hour)
+
+		amount := data.Routes[*driver.RouteID-1].Amount
+		if *driver.RouteID == 3 && created.Before(raised) {
+			amount = 5000
+		}
+
+		data.Transactions = append(data.Transactions, models.Transaction{
+			ID:          i + 1,
+			PassengerID: passenger.ID,
+			DriverID:    driver.ID,
+			RouteID:     driver.RouteID,
+			Amount:      amount,
+			Fare:        &amount,
+			CreatedAt:   &created,
+		})
+
+		if i%4 == 0 {
+			data.Reviews = append(data.Reviews, models.Review{
+				ID:          len(data.Reviews) + 1,
+				PassengerID: passenger.ID,
+				DriverID:    driver.ID,
+				Comment:     comments[len(data.Reviews)%len(comments)],
+				Star:        5 - len(data.Reviews)%3,
+				CreatedAt:   created,
+			})
+		}
+	}
+
+	data.BlockedAccounts = []models.BlockedAccount{
+		{ID: 1, UserID: data.Drivers[len(data.Drivers)-1].ID},
+	}
+
+	return data
+}
@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+type fieldKind int
+
+const (
+	kindText fieldKind = iota
+	kindNumber
+	kindBool
+	kindTime
+)
+
+// listField maps a public field name to its column. Fields that can be
+// sorted on must also appear under the same name in the JSON of the row,
+// because the cursor is built from the last row of a page.
+//
+// Any column can be NULL. A NULL text, number or bool sorts as the zero value
+// of its kind, which is what it is scanned into and so what a cursor holds. A
+// NULL time sorts before every other time, and a zero time in a cursor stands
+// for NULL, since databases do not agree on a zero time.
+type listField struct {
+	Column     string
+	Kind       fieldKind
+	FilterOnly bool
+}
+
+// listSpec describes what a list endpoint can be sorted and filtered by. ID
+// names the unique field used to break ties between equal sort values. Lists
+// of soft deleted entities name the deleted_at column in SoftDelete; they show
+// live rows unless the "deleted" filter asks for deleted ones.
+type listSpec struct {
+	Fields      map[string]listField
+	ID          string
+	DefaultSort string
+	DefaultDesc bool
+	SoftDelete  string
+}
+
+// listCursor is the position after the last row of a page. Sort and order are
+// kept so a cursor cannot be replayed against a different ordering.
+type listCursor struct {
+	Sort  string          `json:"s"`
+	Desc  bool            `json:"d"`
+	Value json.RawMessage `json:"v"`
+	ID    json.RawMessage `json:"i"`
+}
+
+type listPlan struct {
+	spec    listSpec
+	sort    string
+	desc    bool
+	limit   int
+	deleted bool
+	filters []listFilter
+	cursor  *listPosition
+}
+
+type listFilter struct {
+	field listField
+	op    string
+	value any
+}
+
+// listPosition is a decoded cursor: the sort value and id of the last row of
+// the previous page. The value of a NULL time is nil.
+type listPosition struct {
+	value any
+	id    any
+}
+
+// newListPlan validates q against spec and resolves the sort order, page
+// size, filters and cursor it asks for.
+func newListPlan(spec listSpec, q dto.ListQuery) (listPlan, error) {
+	plan := listPlan{
+		spec:  spec,
+		sort:  spec.DefaultSort,
+		desc:  spec.DefaultDesc,
+		limit: q.Limit,
+	}
+
+	if q.Sort != "" {
+		plan.sort = q.Sort
+	}
+	if q.Order != "" {
+		plan.desc = q.Order == "desc"
+	}
+	if plan.limit <= 0 {
+		plan.limit = defaultListLimit
+	}
+	if plan.limit > maxListLimit {
+		plan.limit = maxListLimit
+	}
+
+	sortField, ok := spec.Fields[plan.sort]
+	if !ok || sortField.FilterOnly {
+		return plan, fmt.Errorf("%w: cannot sort by %q", helper.ErrInvalidInput, plan.sort)
+	}
+	idField := spec.Fields[spec.ID]
+
+	for name, raw := range q.Filters {
+		if name == "deleted" && spec.SoftDelete != "" {
+			var err error
+			if plan.deleted, err = strconv.ParseBool(raw); err != nil {
+				return plan, fmt.Errorf("%w: filter \"deleted\": %v", helper.ErrInvalidInput, err)
+			}
+			continue
+		}
+
+		field, op := lookupFilter(spec, name)
+		if field == nil {
+			return plan, fmt.Errorf("%w: unknown filter %q", helper.ErrInvalidInput, name)
+		}
+
+		value, err := parseField(field.Kind, raw)
+		if err != nil {
+			return plan, fmt.Errorf("%w: filter %q: %v", helper.ErrInvalidInput, name, err)
+		}
+
+		plan.filters = append(plan.filters, listFilter{field: *field, op: op, value: value})
+	}
+
+	if q.Cursor != "" {
+		cursor, err := decodeCursor(q.Cursor)
+		if err != nil || cursor.Sort != plan.sort || cursor.Desc != plan.desc {
+			return plan, fmt.Errorf("%w: invalid cursor", helper.ErrInvalidInput)
+		}
+
+		value, errValue := decodeField(sortField.Kind, cursor.Value)
+		id, errID := decodeField(idField.Kind, cursor.ID)
+		if errValue != nil || errID != nil {
+			return plan, fmt.Errorf("%w: invalid cursor", helper.ErrInvalidInput)
+		}
+
+		if t, ok := value.(time.Time); ok && t.IsZero() {
+			value = nil
+		}
+		plan.cursor = &listPosition{value: value, id: id}
+	}
+
+	return plan, nil
+}
+
+// applyList adds the filters, keyset condition, ordering and limit of q to
+// query. It asks for one row more than the page size so paginate can tell
+// whether another page follows.
+func applyList(query *gorm.DB, spec listSpec, q dto.ListQuery) (*gorm.DB, listPlan, error) {
+	plan, err := newListPlan(spec, q)
+	if err != nil {
+		return query, plan, err
+	}
+
+	sortField := spec.Fields[plan.sort]
+	idField := spec.Fields[spec.ID]
+
+	if spec.SoftDelete != "" {
+		if plan.deleted {
+			query = query.Where(spec.SoftDelete + " IS NOT NULL")
+		} else {
+			query = query.Where(spec.SoftDelete + " IS NULL")
+		}
+	}
+
+	for _, filter := range plan.filters {
+		query = query.Where(filter.field.Column+" "+filter.op+" ?", filter.value)
+	}
+
+	cmp, dir := ">", "asc"
+	if plan.desc {
+		cmp, dir = "<", "desc"
+	}
+
+	// The id is never NULL, and sorting by the bare column keeps its index
+	// usable.
+	column, nullable := idField.Column, false
+	if plan.sort != spec.ID {
+		column, nullable = sortColumn(sortField), sortField.Kind == kindTime
+	}
+
+	if plan.cursor != nil {
+		switch {
+		case plan.sort == spec.ID:
+			query = query.Where(idField.Column+" "+cmp+" ?", plan.cursor.id)
+		case nullable && plan.cursor.value == nil && !plan.desc:
+			query = query.Where("(("+column+" IS NULL AND "+idField.Column+" > ?) OR "+column+" IS NOT NULL)", plan.cursor.id)
+		case nullable && plan.cursor.value == nil:
+			query = query.Where("("+column+" IS NULL AND "+idField.Column+" < ?)", plan.cursor.id)
+		case nullable && plan.desc:
+			query = query.Where(
+				"("+column+" < ? OR ("+column+" = ? AND "+idField.Column+" < ?) OR "+column+" IS NULL)",
+				plan.cursor.value, plan.cursor.value, plan.cursor.id,
+			)
+		default:
+			query = query.Where(
+				"("+column+" "+cmp+" ? OR ("+column+" = ? AND "+idField.Column+" "+cmp+" ?))",
+				plan.cursor.value, plan.cursor.value, plan.cursor.id,
+			)
+		}
+	}
+
+	// Databases disagree on where NULL goes, so NULL times are put first
+	// explicitly, as MySQL does.
+	if nullable {
+		query = query.Order("CASE WHEN " + column + " IS NULL THEN 0 ELSE 1 END " + dir)
+	}
+	query = query.Order(column + " " + dir)
+	if plan.sort != spec.ID {
+		query = query.Order(idField.Column + " " + dir)
+	}
+
+	return query.Limit(plan.limit + 1), plan, nil
+}
+
+// sortColumn is the expression field is sorted by, which makes a NULL text,
+// number or bool equal to the zero value of its kind.
+func sortColumn(field listField) string {
+	switch field.Kind {
+	case kindNumber:
+		return "COALESCE(" + field.Column + ", 0)"
+	case kindBool:
+		return "COALESCE(" + field.Column + ", false)"
+	case kindTime:
+		return field.Column
+	default:
+		return "COALESCE(" + field.Column + ", '')"
+	}
+}
+
+// lookupFilter resolves a filter name to its field and operator. Number and
+// time fields also accept "<name>_from" (inclusive) and "<name>_to"
+// (exclusive) range filters.
+func lookupFilter(spec listSpec, name string) (*listField, string) {
+	if field, ok := spec.Fields[name]; ok {
+		return &field, "="
+	}
+
+	for suffix, op := range map[string]string{"_from": ">=", "_to": "<"} {
+		base, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+		if field, ok := spec.Fields[base]; ok && (field.Kind == kindNumber || field.Kind == kindTime) {
+			return &field, op
+		}
+	}
+
+	return nil, ""
+}
+
+// paginate trims the extra row fetched by applyList and builds the cursor of
+// the next page from the last row that is returned.
+func paginate[T any](rows []T, plan listPlan) ([]T, dto.PageInfo, error) {
+	var page dto.PageInfo
+
+	if len(rows) <= plan.limit {
+		return rows, page, nil
+	}
+
+	rows = rows[:plan.limit]
+
+	raw, err := json.Marshal(rows[len(rows)-1])
+	if err != nil {
+		return rows, page, err
+	}
+
+	var last map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &last); err != nil {
+		return rows, page, err
+	}
+
+	cursor, err := json.Marshal(listCursor{
+		Sort:  plan.sort,
+		Desc:  plan.desc,
+		Value: last[plan.sort],
+		ID:    last[plan.spec.ID],
+	})
+	if err != nil {
+		return rows, page, err
+	}
+
+	page.NextCursor = base64.RawURLEncoding.EncodeToString(cursor)
+	page.HasMore = true
+
+	return rows, page, nil
+}
+
+func decodeCursor(s string) (cursor listCursor, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&cursor)
+
+	return cursor, err
+}
+
+func parseField(kind fieldKind, raw string) (any, error) {
+	switch kind {
+	case kindNumber:
+		return strconv.ParseInt(raw, 10, 64)
+	case kindBool:
+		return strconv.ParseBool(raw)
+	case kindTime:
+		return helper.ParseTime(raw)
+	default:
+		return raw, nil
+	}
+}
+
+func decodeField(kind fieldKind, raw json.RawMessage) (any, error) {
+	switch kind {
+	case kindNumber:
+		var v int64
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case kindBool:
+		var v bool
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case kindTime:
+		var v time.Time
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	default:
+		var v string
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+)
+
+// memoryRow is one row of an in-memory list together with the value of every
+// field of its listSpec, keyed by field name. Numbers are int64, times are
+// time.Time and a NULL column is nil.
+type memoryRow[T any] struct {
+	Row    T
+	Values map[string]any
+}
+
+// listMemory is the in-memory counterpart of applyList and paginate. It
+// resolves q with the same listPlan, so both implementations accept the same
+// queries and hand out interchangeable cursors.
+func listMemory[T any](rows []memoryRow[T], spec listSpec, q dto.ListQuery) ([]T, dto.PageInfo, error) {
+	plan, err := newListPlan(spec, q)
+	if err != nil {
+		return nil, dto.PageInfo{}, err
+	}
+
+	softDelete := ""
+	for name, field := range spec.Fields {
+		if spec.SoftDelete != "" && field.Column == spec.SoftDelete {
+			softDelete = name
+		}
+	}
+
+	matched := make([]memoryRow[T], 0, len(rows))
+	for _, row := range rows {
+		if softDelete != "" && (row.Values[softDelete] != nil) != plan.deleted {
+			continue
+		}
+		if !matchFilters(row.Values, plan) {
+			continue
+		}
+		if plan.cursor != nil && !afterCursor(row.Values, plan) {
+			continue
+		}
+
+		matched = append(matched, row)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return rowLess(matched[i].Values, matched[j].Values, plan)
+	})
+
+	if len(matched) > plan.limit+1 {
+		matched = matched[:plan.limit+1]
+	}
+
+	res := make([]T, 0, len(matched))
+	for _, row := range matched {
+		res = append(res, row.Row)
+	}
+
+	res, page, err := paginate(res, plan)
+	if err != nil {
+		return nil, page, helper.ErrInternal
+	}
+
+	return res, page, nil
+}
+
+func matchFilters(values map[string]any, plan listPlan) bool {
+	for _, filter := range plan.filters {
+		cmp, ok := compareValues(values[fieldName(plan.spec, filter.field)], filter.value)
+		if !ok {
+			return false
+		}
+
+		switch filter.op {
+		case "=":
+			ok = cmp == 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// afterCursor reports whether the row comes after the cursor in the order of
+// plan, the same keyset condition applyList writes in SQL.
+func afterCursor(values map[string]any, plan listPlan) bool {
+	want := 1
+	if plan.desc {
+		want = -1
+	}
+
+	id, ok := compareValues(values[plan.spec.ID], plan.cursor.id)
+	if !ok {
+		return false
+	}
+	if plan.sort == plan.spec.ID {
+		return id == want
+	}
+
+	value := compareNullable(sortValue(plan, values[plan.sort]), plan.cursor.value)
+
+	return value == want || (value == 0 && id == want)
+}
+
+func rowLess(a, b map[string]any, plan listPlan) bool {
+	for _, name := range []string{plan.sort, plan.spec.ID} {
+		cmp := compareNullable(sortValue(plan, a[name]), sortValue(plan, b[name]))
+		if cmp == 0 {
+			continue
+		}
+		if plan.desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+
+	return false
+}
+
+// sortValue is what value sorts as, like sortColumn: NULL becomes the zero
+// value of the sort field, unless that is a time.
+func sortValue(plan listPlan, value any) any {
+	if value != nil {
+		return value
+	}
+
+	switch plan.spec.Fields[plan.sort].Kind {
+	case kindNumber:
+		return int64(0)
+	case kindBool:
+		return false
+	case kindTime:
+		return nil
+	default:
+		return ""
+	}
+}
+
+// fieldName finds the public name of field in spec, since filters keep the
+// field itself.
+func fieldName(spec listSpec, field listField) string {
+	for name, f := range spec.Fields {
+		if f.Column == field.Column {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// compareNullable orders NULL before every other value, as MySQL does.
+func compareNullable(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	cmp, _ := compareValues(a, b)
+	return cmp
+}
+
+// compareValues compares two values of the same field kind. Like SQL, it
+// reports false when either side is NULL.
+func compareValues(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case string:
+		b, ok := b.(string)
+		return strings.Compare(a, b), ok
+	case int64:
+		b, ok := b.(int64)
+		switch {
+		case !ok:
+			return 0, false
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		b, ok := b.(bool)
+		switch {
+		case !ok:
+			return 0, false
+		case a == b:
+			return 0, true
+		case !a:
+			return -1, true
+		}
+		return 1, true
+	case time.Time:
+		b, ok := b.(time.Time)
+		return a.Compare(b), ok
+	}
+
+	return 0, false
+}
@@ -0,0 +1,1016 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryData is the content a MemoryDashboardRepo starts with. Rows are
+// given as they would be stored in the database; IDs left at zero are
+// assigned.
+type MemoryData struct {
+	Users           []models.User
+	Drivers         []models.DriverDetails
+	Passengers      []models.PassengerDetails
+	Routes          []models.Route
+	RouteFares      []models.RouteFare
+	Reviews         []models.Review
+	Transactions    []models.Transaction
+	BlockedAccounts []models.BlockedAccount
+	ResetPasswords  []models.ResetPassword
+}
+
+// MemoryDashboardRepo is a DashboardRepo that keeps everything in memory. It
+// returns the same results and errors as DashboardRepoImpl, which makes it
+// usable for tests of the layers above and for the demo mode. Audit entries
+// are kept in memory as well, see AuditLogs.
+type MemoryDashboardRepo struct {
+	mu sync.RWMutex
+
+	users        map[string]models.User
+	drivers      map[string]models.DriverDetails
+	passengers   map[string]models.PassengerDetails
+	routes       map[uint]models.Route
+	fares        map[uint]models.RouteFare
+	reviews      map[int]models.Review
+	transactions map[int]models.Transaction
+	blocked      map[string]models.BlockedAccount
+	resets       map[string]models.ResetPassword
+	audit        []models.AuditLog
+
+	nextRoute   uint
+	nextFare    uint
+	nextBlocked int
+}
+
+func (a *MemoryDashboardRepo) GetAllDrivers(c context.Context, q dto.ListQuery) ([]models.Drivers, dto.PageInfo, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	rows := make([]memoryRow[models.Drivers], 0, len(a.drivers))
+	for _, d := range a.drivers {
+		u, ok := a.users[d.ID]
+		if !ok {
+			continue
+		}
+
+		row := models.Drivers{
+			ID:             d.ID,
+			Email:          u.Email,
+			Name:           d.Name,
+			PhoneNumber:    d.PhoneNumber,
+			LicenseNumber:  d.LicenseNumber,
+			SIM:            d.SIM,
+			Verified:       d.Verified,
+			ProfilePicture: d.ProfilePicture,
+			KTP:            d.KTP,
+			Status:         d.Status,
+			DeletedAt:      deletedAt(d.DeletedAt),
+			Version:        d.Version,
+		}
+
+		rows = append(rows, memoryRow[models.Drivers]{Row: row, Values: map[string]any{
+			"id":             d.ID,
+			"email":          u.Email,
+			"name":           d.Name,
+			"phone_number":   d.PhoneNumber,
+			"license_number": d.LicenseNumber,
+			"verified":       d.Verified,
+			"status":         d.Status,
+			"route_id":       nullableID(d.RouteID),
+			"deleted_at":     nullableTime(row.DeletedAt),
+		}})
+	}
+
+	return listMemory(rows, driverList, q)
+}
+
+func (a *MemoryDashboardRepo) GetAllPassengers(c context.Context, q dto.ListQuery) ([]models.Passengers, dto.PageInfo, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	rows := make([]memoryRow[models.Passengers], 0, len(a.passengers))
+	for _, p := range a.passengers {
+		u, ok := a.users[p.ID]
+		if !ok {
+			continue
+		}
+
+		row := models.Passengers{
+			ID:          p.ID,
+			Email:       u.Email,
+			Name:        p.Name,
+			DateOfBirth: p.DateOfBirth,
+			Age:         p.Age,
+			DeletedAt:   deletedAt(p.DeletedAt),
+		}
+
+		rows = append(rows, memoryRow[models.Passengers]{Row: row, Values: map[string]any{
+			"id":            p.ID,
+			"email":         u.Email,
+			"name":          p.Name,
+			"date_of_birth": p.DateOfBirth,
+			"age":           int64(p.Age),
+			"deleted_at":    nullableTime(row.DeletedAt),
+		}})
+	}
+
+	return listMemory(rows, passengerList, q)
+}
+
+func (a *MemoryDashboardRepo) GetDriverByID(c context.Context, id string) (res models.Drivers, err error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	d, ok := a.drivers[id]
+	u, hasUser := a.users[id]
+	if !ok || !hasUser || d.DeletedAt.Valid {
+		return res, helper.ErrNotFound
+	}
+
+	return driverRow(d, u), nil
+}
+
+// driverRow builds the row driverByID selects in DashboardRepoImpl.
+func driverRow(d models.DriverDetails, u models.User) models.Drivers {
+	return models.Drivers{
+		ID:             d.ID,
+		Email:          u.Email,
+		Name:           d.Name,
+		PhoneNumber:    d.PhoneNumber,
+		LicenseNumber:  d.LicenseNumber,
+		SIM:            d.SIM,
+		Verified:       d.Verified,
+		ProfilePicture: d.ProfilePicture,
+		KTP:            d.KTP,
+		Version:        d.Version,
+	}
+}
+
+func (a *MemoryDashboardRepo) GetPassengerByID(c context.Context, id string) (res models.Passengers, err error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	p, ok := a.passengers[id]
+	u, hasUser := a.users[id]
+	if !ok || !hasUser || p.DeletedAt.Valid {
+		return res, helper.ErrNotFound
+	}
+
+	return models.Passengers{
+		ID:    p.ID,
+		Email: u.Email,
+		Name:  p.Name,
+	}, nil
+}
+
+func (a *MemoryDashboardRepo) GetAllReview(c context.Context, q dto.ListQuery) ([]models.Reviews, dto.PageInfo, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	rows := make([]memoryRow[models.Reviews], 0, len(a.reviews))
+	for _, r := range a.reviews {
+		row := a.review(r)
+		rows = append(rows, memoryRow[models.Reviews]{Row: row, Values: map[string]any{
+			"id":             int64(r.ID),
+			"passenger_name": row.PassengerName,
+			"driver_name":    row.DriverName,
+			"star":           int64(r.Star),
+			"passenger_id":   r.PassengerID,
+			"driver_id":      r.DriverID,
+		}})
+	}
+
+	return listMemory(rows, reviewList, q)
+}
+
+func (a *MemoryDashboardRepo) GetReviewById(c context.Context, id string) (res models.Reviews, err error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	key, err := strconv.Atoi(id)
+	if err != nil {
+		return res, helper.ErrNotFound
+	}
+
+	r, ok := a.reviews[key]
+	if !ok {
+		return res, helper.ErrNotFound
+	}
+
+	return a.review(r), nil
+}
+
+// review joins r with the passenger and driver it is about, like the queries
+// of DashboardRepoImpl do.
+func (a *MemoryDashboardRepo) review(r models.Review) models.Reviews {
+	passenger, driver := a.names(r.PassengerID, r.DriverID)
+
+	return models.Reviews{
+		ID:            r.ID,
+		PassengerName: passenger,
+		DriverName:    driver,
+		Comment:       r.Comment,
+		Star:          r.Star,
+	}
+}
+
+// names looks up the names of a passenger and a driver. An account that is
+// gone reads as anonymizedName, as with the LEFT JOINs of DashboardRepoImpl.
+func (a *MemoryDashboardRepo) names(passengerID, driverID string) (passenger, driver string) {
+	passenger, driver = anonymizedName, anonymizedName
+	if p, ok := a.passengers[passengerID]; ok {
+		passenger = p.Name
+	}
+	if d, ok := a.drivers[driverID]; ok {
+		driver = d.Name
+	}
+
+	return passenger, driver
+}
+
+func (a *MemoryDashboardRepo) GetAllTripHistories(c context.Context, q dto.ListQuery) ([]models.Histories, dto.PageInfo, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	rows := make([]memoryRow[models.Histories], 0, len(a.transactions))
+	for _, t := range a.transactions {
+		passenger, driver := a.names(t.PassengerID, t.DriverID)
+		// Trips without a route are listed with an empty one, as the LEFT JOIN
+		// of DashboardRepoImpl does.
+		var r models.Route
+		if t.RouteID != nil {
+			r = a.routes[*t.RouteID]
+		}
+
+		row := models.Histories{
+			ID:            t.ID,
+			PassengerName: passenger,
+			DriverName:    driver,
+			Amount:        t.Amount,
+			Fare:          t.Fare,
+			Route:         r.RouteName,
+		}
+		if t.CreatedAt != nil {
+			row.CreatedAt = *t.CreatedAt
+		}
+
+		rows = append(rows, memoryRow[models.Histories]{Row: row, Values: map[string]any{
+			"id":             int64(t.ID),
+			"passenger_name": passenger,
+			"driver_name":    driver,
+			"amount":         int64(t.Amount),
+			"route":          r.RouteName,
+			"created_at":     nullableTime(t.CreatedAt),
+			"passenger_id":   t.PassengerID,
+			"driver_id":      t.DriverID,
+			"route_id":       nullableID(t.RouteID),
+		}})
+	}
+
+	return listMemory(rows, historyList, q)
+}
+
+func (a *MemoryDashboardRepo) EditAmountRoute(c context.Context, data models.Route, id string) (res models.Route, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, ok := routeKey(id)
+	before, found := a.routes[key]
+	if !ok || !found {
+		return res, helper.ErrNotFound
+	}
+
+	if err := checkVersion(before.Version, data.Version); err != nil {
+		return res, err
+	}
+
+	fare, prev, err := a.planFare(c, key, data.Amount, time.Now())
+	if err != nil {
+		return res, err
+	}
+
+	res = before
+	res.Amount = data.Amount
+	res.Version++
+
+	if err := a.record(c, "route.update", "route", id, before, res); err != nil {
+		return models.Route{}, helper.ErrDatabase
+	}
+	a.routes[key] = res
+	a.addFare(fare, prev)
+
+	return res, nil
+}
+
+func (a *MemoryDashboardRepo) BlockAccount(c context.Context, data models.BlockedAccount) (res models.BlockedAccount, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if u, ok := a.users[data.UserID]; !ok || u.DeletedAt.Valid {
+		return res, helper.ErrNotFound
+	}
+	if _, ok := a.blocked[data.UserID]; ok {
+		return res, helper.ErrDuplicateEntry
+	}
+
+	a.nextBlocked++
+	data.ID = a.nextBlocked
+
+	if err := a.record(c, "account.block", "account", data.UserID, nil, data); err != nil {
+		return res, helper.ErrDatabase
+	}
+	a.blocked[data.UserID] = data
+
+	return data, nil
+}
+
+func (a *MemoryDashboardRepo) UnblockAccount(c context.Context, id string) (res string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	before, ok := a.blocked[id]
+	if !ok {
+		return res, helper.ErrNotFound
+	}
+
+	if err := a.record(c, "account.unblock", "account", id, before, nil); err != nil {
+		return res, helper.ErrDatabase
+	}
+	delete(a.blocked, id)
+
+	return "Berhasil membuka blokir akun", nil
+}
+
+func (a *MemoryDashboardRepo) IsBlocked(c context.Context, id string) (bool, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, ok := a.blocked[id]
+	return ok, nil
+}
+
+func (a *MemoryDashboardRepo) GetAllBlcokAccount(c context.Context, q dto.ListQuery) ([]models.BlockDriver, dto.PageInfo, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	rows := make([]memoryRow[models.BlockDriver], 0, len(a.blocked))
+	for _, b := range a.blocked {
+		u, hasUser := a.users[b.UserID]
+		d, hasDriver := a.drivers[b.UserID]
+		if !hasUser || !hasDriver {
+			continue
+		}
+
+		row := models.BlockDriver{
+			ID:    b.UserID,
+			Email: u.Email,
+			Name:  d.Name,
+		}
+
+		rows = append(rows, memoryRow[models.BlockDriver]{Row: row, Values: map[string]any{
+			"id":    b.UserID,
+			"email": u.Email,
+			"name":  d.Name,
+		}})
+	}
+
+	return listMemory(rows, blockedList, q)
+}
+
+func (a *MemoryDashboardRepo) SetDriverStatusVerified(c context.Context, id string, version uint) (res models.Drivers, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// The database reads the row back through a join with users, and rolls
+	// back when that finds nothing.
+	before, ok := a.drivers[id]
+	u, hasUser := a.users[id]
+	if !ok || !hasUser || before.DeletedAt.Valid {
+		return res, helper.ErrNotFound
+	}
+	if err := checkVersion(before.Version, version); err != nil {
+		return res, err
+	}
+
+	after := before
+	after.Verified = true
+	after.Version++
+
+	if err := a.record(c, "driver.verify", "driver", id, before, after); err != nil {
+		return res, helper.ErrDatabase
+	}
+	a.drivers[id] = after
+
+	return driverRow(after, u), nil
+}
+
+func (a *MemoryDashboardRepo) DeleteDriver(c context.Context, id string, version uint) (res dto.DeletionReport, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	before, ok := a.drivers[id]
+	if !ok || before.DeletedAt.Valid {
+		return res, helper.ErrNotFound
+	}
+	if err := checkVersion(before.Version, version); err != nil {
+		return res, err
+	}
+
+	after := before
+	after.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	after.Version++
+
+	res = a.deletionReport(id)
+	if err := a.record(c, "driver.delete", "driver", id, before, res); err != nil {
+		return dto.DeletionReport{}, helper.ErrDatabase
+	}
+
+	a.drivers[id] = after
+	a.deleteAccount(id)
+
+	return res, nil
+}
+
+func (a *MemoryDashboardRepo) DeleteUser(c context.Context, id string) (res dto.DeletionReport, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	before, ok := a.passengers[id]
+	if !ok || before.DeletedAt.Valid {
+		return res, helper.ErrNotFound
+	}
+
+	after := before
+	after.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+
+	res = a.deletionReport(id)
+	if err := a.record(c, "passenger.delete", "passenger", id, before, res); err != nil {
+		return dto.DeletionReport{}, helper.ErrDatabase
+	}
+
+	a.passengers[id] = after
+	a.deleteAccount(id)
+
+	return res, nil
+}
+
+// deletionReport counts what deleteAccount is about to remove for id.
+func (a *MemoryDashboardRepo) deletionReport(id string) dto.DeletionReport {
+	res := dto.DeletionReport{ID: id, Details: 1}
+
+	if u, ok := a.users[id]; ok && !u.DeletedAt.Valid {
+		res.Users = 1
+	}
+	if _, ok := a.resets[id]; ok {
+		res.ResetPasswords = 1
+	}
+
+	return res
+}
+
+// deleteAccount mirrors the function of the same name in DashboardRepoImpl
+// for everything except the details row.
+func (a *MemoryDashboardRepo) deleteAccount(id string) {
+	if u, ok := a.users[id]; ok && !u.DeletedAt.Valid {
+		u.Password = ""
+		u.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		a.users[id] = u
+	}
+
+	delete(a.resets, id)
+}
+
+func (a *MemoryDashboardRepo) RestoreDriver(c context.Context, id string) (res string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	before, ok := a.drivers[id]
+	if !ok || !before.DeletedAt.Valid || before.PurgedAt != nil {
+		return res, helper.ErrNotFound
+	}
+
+	after := before
+	after.DeletedAt = gorm.DeletedAt{}
+	after.Version++
+
+	if err := a.record(c, "driver.restore", "driver", id, before, after); err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	a.drivers[id] = after
+	a.restoreUser(id)
+
+	return "Berhasil memulihkan driver, kata sandi harus diatur ulang", nil
+}
+
+func (a *MemoryDashboardRepo) RestoreUser(c context.Context, id string) (res string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	before, ok := a.passengers[id]
+	if !ok || !before.DeletedAt.Valid || before.PurgedAt != nil {
+		return res, helper.ErrNotFound
+	}
+
+	after := before
+	after.DeletedAt = gorm.DeletedAt{}
+
+	if err := a.record(c, "passenger.restore", "passenger", id, before, after); err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	a.passengers[id] = after
+	a.restoreUser(id)
+
+	return "Berhasil memulihkan passenger, kata sandi harus diatur ulang", nil
+}
+
+func (a *MemoryDashboardRepo) restoreUser(id string) {
+	if u, ok := a.users[id]; ok {
+		u.DeletedAt = gorm.DeletedAt{}
+		a.users[id] = u
+	}
+}
+
+func (a *MemoryDashboardRepo) AddRoute(c context.Context, data models.Route) (res models.Route, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data.ID = a.nextRoute + 1
+	data.Version = 1
+
+	fare, prev, err := a.planFare(c, data.ID, data.Amount, time.Now())
+	if err != nil {
+		return res, err
+	}
+
+	if err := a.record(c, "route.create", "route", strconv.FormatUint(uint64(data.ID), 10), nil, data); err != nil {
+		return res, helper.ErrDatabase
+	}
+	a.nextRoute = data.ID
+	a.routes[data.ID] = data
+	a.addFare(fare, prev)
+
+	return data, nil
+}
+
+func (a *MemoryDashboardRepo) Report(c context.Context, w dto.ReportWindow) (res dto.Report, err error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var accounts dto.CommonReport
+	for _, p := range a.passengers {
+		if !p.DeletedAt.Valid {
+			accounts.TotalPassenger++
+		}
+	}
+	for _, d := range a.drivers {
+		if !d.DeletedAt.Valid {
+			accounts.TotalDriver++
+		}
+	}
+
+	builder := newReportBuilder(w)
+	for _, t := range a.transactions {
+		if t.CreatedAt == nil || t.CreatedAt.Before(w.From) || !t.CreatedAt.Before(w.To) {
+			continue
+		}
+
+		trip := reportTrip{
+			RouteID:   t.RouteID,
+			Trips:     1,
+			Amount:    t.Amount,
+			Fare:      t.Fare,
+			CreatedAt: *t.CreatedAt,
+		}
+		// An account that was purged leaves an empty ID, NULL in the database.
+		if t.DriverID != "" {
+			trip.DriverID = &t.DriverID
+		}
+		if t.PassengerID != "" {
+			trip.PassengerID = &t.PassengerID
+		}
+		builder.add(trip)
+	}
+
+	return builder.report(accounts), nil
+}
+
+func (a *MemoryDashboardRepo) DriverEarnings(c context.Context, w dto.ReportWindow, id string) (res []dto.DriverEarnings, err error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var drivers []models.DriverDetails
+	for _, d := range a.drivers {
+		if !d.DeletedAt.Valid && (id == "" || d.ID == id) {
+			drivers = append(drivers, d)
+		}
+	}
+
+	if id != "" && len(drivers) == 0 {
+		return res, helper.ErrNotFound
+	}
+
+	builder := newEarningsBuilder(w, drivers)
+	for _, t := range a.transactions {
+		if t.CreatedAt == nil || t.CreatedAt.Before(w.From) || !t.CreatedAt.Before(w.To) || t.DriverID == "" {
+			continue
+		}
+
+		builder.addTrip(reportTrip{DriverID: &t.DriverID, Trips: 1, Amount: t.Amount, CreatedAt: *t.CreatedAt})
+	}
+	for _, r := range a.reviews {
+		if r.CreatedAt.Before(w.From) || !r.CreatedAt.Before(w.To) {
+			continue
+		}
+
+		builder.addReview(earningsReview{DriverID: r.DriverID, Reviews: 1, Star: r.Star})
+	}
+
+	return builder.earnings(), nil
+}
+
+func (a *MemoryDashboardRepo) Demand(c context.Context, w dto.ReportWindow) (res dto.Demand, err error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	builder := newDemandBuilder(w)
+	for _, t := range a.transactions {
+		if t.CreatedAt == nil || t.CreatedAt.Before(w.From) || !t.CreatedAt.Before(w.To) {
+			continue
+		}
+
+		builder.add(reportTrip{RouteID: t.RouteID, Trips: 1, Amount: t.Amount, CreatedAt: *t.CreatedAt})
+	}
+
+	return builder.demand(), nil
+}
+
+func (a *MemoryDashboardRepo) GetRoutes(c context.Context) ([]models.Route, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	now := time.Now()
+
+	res := make([]models.Route, 0, len(a.routes))
+	for _, r := range a.routes {
+		res = append(res, a.routeAt(r, now))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+
+	return res, nil
+}
+
+func (a *MemoryDashboardRepo) GetRouteByID(c context.Context, id string) (res models.Route, err error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	key, ok := routeKey(id)
+	res, found := a.routes[key]
+	if !ok || !found {
+		return models.Route{}, helper.ErrNotFound
+	}
+
+	return a.routeAt(res, time.Now()), nil
+}
+
+func (a *MemoryDashboardRepo) DeleteRoute(c context.Context, id string, version uint) (res string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, ok := routeKey(id)
+	before, found := a.routes[key]
+	if !ok || !found {
+		return res, helper.ErrNotFound
+	}
+	if err := checkVersion(before.Version, version); err != nil {
+		return res, err
+	}
+
+	// driver_details.route_id has no ON DELETE rule, so the database refuses
+	// to delete a route that drivers are still assigned to. Trips keep
+	// theirs as NULL.
+	for _, d := range a.drivers {
+		if d.RouteID != nil && *d.RouteID == key {
+			return res, helper.ErrInUse
+		}
+	}
+
+	if err := a.record(c, "route.delete", "route", id, before, nil); err != nil {
+		return res, helper.ErrDatabase
+	}
+	delete(a.routes, key)
+	for id, f := range a.fares {
+		if f.RouteID == key {
+			delete(a.fares, id)
+		}
+	}
+	for i, t := range a.transactions {
+		if t.RouteID != nil && *t.RouteID == key {
+			t.RouteID = nil
+			a.transactions[i] = t
+		}
+	}
+
+	return "Berhasil menghapus rute!", nil
+}
+
+func (a *MemoryDashboardRepo) GetRouteFares(c context.Context, id string) ([]models.RouteFare, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	key, ok := routeKey(id)
+	if _, found := a.routes[key]; !ok || !found {
+		return nil, helper.ErrNotFound
+	}
+
+	return a.routeFares(key), nil
+}
+
+func (a *MemoryDashboardRepo) ScheduleRouteFare(c context.Context, id string, data models.RouteFare) (res models.RouteFare, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, ok := routeKey(id)
+	if _, found := a.routes[key]; !ok || !found {
+		return res, helper.ErrNotFound
+	}
+
+	fare, prev, err := a.planFare(c, key, data.Amount, data.EffectiveFrom)
+	if err != nil {
+		return res, err
+	}
+
+	if err := a.record(c, "route.fare_schedule", "route", id, nil, fare); err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	return a.addFare(fare, prev), nil
+}
+
+func (a *MemoryDashboardRepo) CancelRouteFare(c context.Context, id string, fareID string) (res models.RouteFare, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, ok := routeKey(id)
+	fareKey, fareOk := routeKey(fareID)
+	res, found := a.fares[fareKey]
+	if !ok || !fareOk || !found || res.RouteID != key {
+		return models.RouteFare{}, helper.ErrNotFound
+	}
+
+	if !res.EffectiveFrom.After(time.Now()) {
+		return models.RouteFare{}, helper.ErrFareInEffect
+	}
+
+	if err := a.record(c, "route.fare_cancel", "route", id, res, nil); err != nil {
+		return models.RouteFare{}, helper.ErrDatabase
+	}
+
+	fares := a.routeFares(key)
+	for i, f := range fares {
+		if f.ID == res.ID && i > 0 {
+			prev := fares[i-1]
+			prev.EffectiveTo = res.EffectiveTo
+			a.fares[prev.ID] = prev
+		}
+	}
+	delete(a.fares, res.ID)
+
+	return res, nil
+}
+
+func (a *MemoryDashboardRepo) ApplyRouteFares(c context.Context, now time.Time) ([]models.Route, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ids := make([]uint, 0, len(a.routes))
+	for id := range a.routes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var res []models.Route
+	for _, id := range ids {
+		before := a.routes[id]
+
+		fare, ok := a.fareAt(id, now)
+		if !ok || fare.Amount == before.Amount {
+			continue
+		}
+
+		after := before
+		after.Amount = fare.Amount
+		after.Version++
+
+		if err := a.record(c, "route.fare_apply", "route", strconv.FormatUint(uint64(id), 10), before, after); err != nil {
+			return nil, helper.ErrDatabase
+		}
+		a.routes[id] = after
+		res = append(res, after)
+	}
+
+	return res, nil
+}
+
+// routeFares returns the fares of a route, oldest first.
+func (a *MemoryDashboardRepo) routeFares(routeID uint) []models.RouteFare {
+	res := []models.RouteFare{}
+	for _, f := range a.fares {
+		if f.RouteID == routeID {
+			res = append(res, f)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].EffectiveFrom.Before(res[j].EffectiveFrom) })
+
+	return res
+}
+
+func (a *MemoryDashboardRepo) fareAt(routeID uint, at time.Time) (models.RouteFare, bool) {
+	for _, f := range a.fares {
+		if f.RouteID == routeID && !f.EffectiveFrom.After(at) && (f.EffectiveTo == nil || f.EffectiveTo.After(at)) {
+			return f, true
+		}
+	}
+
+	return models.RouteFare{}, false
+}
+
+// routeAt is r with the fare in effect at the given time, as routesAt
+// selects it.
+func (a *MemoryDashboardRepo) routeAt(r models.Route, at time.Time) models.Route {
+	if fare, ok := a.fareAt(r.ID, at); ok {
+		r.Amount = fare.Amount
+	}
+
+	return r
+}
+
+// planFare builds the fare insertFare would add, together with the ID of the
+// fare it cuts short, if any. Nothing is changed until addFare.
+func (a *MemoryDashboardRepo) planFare(c context.Context, routeID uint, amount int, from time.Time) (res models.RouteFare, prev uint, err error) {
+	from = fareTime(from)
+
+	res = models.RouteFare{
+		RouteID:       routeID,
+		Amount:        amount,
+		EffectiveFrom: from,
+		CreatedBy:     helper.ActorFrom(c).ID,
+	}
+
+	for _, f := range a.routeFares(routeID) {
+		switch {
+		case f.EffectiveFrom.Equal(from):
+			return models.RouteFare{}, 0, helper.ErrFareExists
+		case f.EffectiveFrom.Before(from):
+			prev = f.ID
+		case res.EffectiveTo == nil:
+			next := f.EffectiveFrom
+			res.EffectiveTo = &next
+		}
+	}
+
+	return res, prev, nil
+}
+
+func (a *MemoryDashboardRepo) addFare(fare models.RouteFare, prev uint) models.RouteFare {
+	if prev != 0 {
+		p := a.fares[prev]
+		from := fare.EffectiveFrom
+		p.EffectiveTo = &from
+		a.fares[prev] = p
+	}
+
+	a.nextFare++
+	fare.ID = a.nextFare
+	fare.CreatedAt = time.Now()
+	a.fares[fare.ID] = fare
+
+	return fare
+}
+
+// AuditLogs returns the audit entries recorded so far, oldest first.
+func (a *MemoryDashboardRepo) AuditLogs() []models.AuditLog {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return append([]models.AuditLog{}, a.audit...)
+}
+
+// record appends an audit entry. It is called before the change is applied,
+// so a change whose entry cannot be built is not made, as with writeAudit.
+func (a *MemoryDashboardRepo) record(c context.Context, action string, targetType string, targetID string, before any, after any) error {
+	entry, err := auditEntry(c, action, targetType, targetID, before, after)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = uint(len(a.audit) + 1)
+	entry.CreatedAt = time.Now()
+	a.audit = append(a.audit, entry)
+
+	return nil
+}
+
+func routeKey(id string) (uint, bool) {
+	key, err := strconv.ParseUint(id, 10, 64)
+	return uint(key), err == nil
+}
+
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+
+	return &d.Time
+}
+
+func nullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return *t
+}
+
+func nullableID(id *uint) any {
+	if id == nil {
+		return nil
+	}
+
+	return int64(*id)
+}
+
+func NewMemoryDashboardRepo(data MemoryData) *MemoryDashboardRepo {
+	a := &MemoryDashboardRepo{
+		users:        map[string]models.User{},
+		drivers:      map[string]models.DriverDetails{},
+		passengers:   map[string]models.PassengerDetails{},
+		routes:       map[uint]models.Route{},
+		fares:        map[uint]models.RouteFare{},
+		reviews:      map[int]models.Review{},
+		transactions: map[int]models.Transaction{},
+		blocked:      map[string]models.BlockedAccount{},
+		resets:       map[string]models.ResetPassword{},
+	}
+
+	for _, u := range data.Users {
+		a.users[u.ID] = u
+	}
+	// Versions start at 1, the column default.
+	for _, d := range data.Drivers {
+		d.Version = max(d.Version, 1)
+		a.drivers[d.ID] = d
+	}
+	for _, p := range data.Passengers {
+		a.passengers[p.ID] = p
+	}
+	for _, r := range data.Routes {
+		if r.ID == 0 {
+			r.ID = a.nextRoute + 1
+		}
+		r.Version = max(r.Version, 1)
+		a.nextRoute = max(a.nextRoute, r.ID)
+		a.routes[r.ID] = r
+	}
+	for _, f := range data.RouteFares {
+		if f.ID == 0 {
+			f.ID = a.nextFare + 1
+		}
+		a.nextFare = max(a.nextFare, f.ID)
+		a.fares[f.ID] = f
+	}
+	for i, r := range data.Reviews {
+		if r.ID == 0 {
+			r.ID = i + 1
+		}
+		a.reviews[r.ID] = r
+	}
+	for i, t := range data.Transactions {
+		if t.ID == 0 {
+			t.ID = i + 1
+		}
+		a.transactions[t.ID] = t
+	}
+	for _, b := range data.BlockedAccounts {
+		if b.ID == 0 {
+			b.ID = a.nextBlocked + 1
+		}
+		a.nextBlocked = max(a.nextBlocked, b.ID)
+		a.blocked[b.UserID] = b
+	}
+	for _, r := range data.ResetPasswords {
+		a.resets[r.UserID] = r
+	}
+
+	return a
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type ProposalRepo interface {
+	CreateProposal(c context.Context, data models.Proposal) (models.Proposal, error)
+	GetProposals(c context.Context, status string) ([]models.Proposal, error)
+	GetProposalByID(c context.Context, id string) (models.Proposal, error)
+	ClaimProposal(c context.Context, id string, status string, decidedBy string, note string) (models.Proposal, error)
+	FinishProposal(c context.Context, id string) (models.Proposal, error)
+	ReleaseProposal(c context.Context, id string) error
+	ExpireProposals(c context.Context, now time.Time) error
+}
+
+type ProposalRepoImpl struct {
+	db *gorm.DB
+}
+
+func (a *ProposalRepoImpl) CreateProposal(c context.Context, data models.Proposal) (models.Proposal, error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&data).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "proposal.create", "proposal", strconv.FormatUint(uint64(data.ID), 10), nil, data)
+	}); err != nil {
+		return data, dbError(err)
+	}
+
+	return data, nil
+}
+
+func (a *ProposalRepoImpl) GetProposals(c context.Context, status string) (res []models.Proposal, err error) {
+	query := a.db.WithContext(c).Order("id desc")
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Find(&res).Error; err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	return res, nil
+}
+
+func (a *ProposalRepoImpl) GetProposalByID(c context.Context, id string) (res models.Proposal, err error) {
+	if err := a.db.WithContext(c).First(&res, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return res, helper.ErrNotFound
+		}
+		return res, helper.ErrDatabase
+	}
+
+	return res, nil
+}
+
+// ClaimProposal moves a pending, unexpired proposal to status. The update is
+// conditional, so of two admins deciding at the same time only one wins; the
+// other gets helper.ErrProposalClosed.
+func (a *ProposalRepoImpl) ClaimProposal(c context.Context, id string, status string, decidedBy string, note string) (res models.Proposal, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before models.Proposal
+		if err := tx.First(&before, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.Proposal{}).
+			Where("id = ? AND status = ? AND expires_at > ?", id, models.ProposalPending, now).
+			Updates(map[string]any{
+				"status":     status,
+				"decided_by": decidedBy,
+				"decided_at": now,
+				"note":       note,
+			})
+
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return helper.ErrProposalClosed
+		}
+
+		if err := tx.First(&res, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "proposal."+status, "proposal", id, before, res)
+	}); err != nil {
+		if errors.Is(err, helper.ErrProposalClosed) {
+			return res, err
+		}
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
+// FinishProposal marks an executing proposal approved once its action went
+// through.
+func (a *ProposalRepoImpl) FinishProposal(c context.Context, id string) (res models.Proposal, err error) {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before models.Proposal
+		if err := tx.First(&before, "id = ? AND status = ?", id, models.ProposalExecuting).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Proposal{}).Where("id = ?", id).Update("status", models.ProposalApproved).Error; err != nil {
+			return err
+		}
+
+		if err := tx.First(&res, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "proposal."+models.ProposalApproved, "proposal", id, before, res)
+	}); err != nil {
+		return res, dbError(err)
+	}
+
+	return res, nil
+}
+
+// ReleaseProposal returns an executing proposal to pending after its action
+// failed, so it can be approved again or rejected.
+func (a *ProposalRepoImpl) ReleaseProposal(c context.Context, id string) error {
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before, after models.Proposal
+		if err := tx.First(&before, "id = ? AND status = ?", id, models.ProposalExecuting).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Proposal{}).Where("id = ?", id).Updates(map[string]any{
+			"status":     models.ProposalPending,
+			"decided_by": "",
+			"decided_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.First(&after, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "proposal.release", "proposal", id, before, after)
+	}); err != nil {
+		return dbError(err)
+	}
+
+	return nil
+}
+
+// executionTimeout is how long a proposal can stay executing. One that is
+// older was left behind by a process that stopped while running the action.
+const executionTimeout = 5 * time.Minute
+
+// ExpireProposals expires pending proposals past their time and returns
+// proposals stuck executing to pending. Whether the action of a stuck
+// proposal went through is not known, so it is left to the admins to approve
+// it again, which fails harmlessly if it did, or to reject it.
+func (a *ProposalRepoImpl) ExpireProposals(c context.Context, now time.Time) error {
+	if err := a.db.WithContext(c).Model(&models.Proposal{}).
+		Where("status = ? AND decided_at <= ?", models.ProposalExecuting, now.Add(-executionTimeout)).
+		Updates(map[string]any{
+			"status":     models.ProposalPending,
+			"decided_by": "",
+			"decided_at": nil,
+		}).Error; err != nil {
+		return helper.ErrDatabase
+	}
+
+	if err := a.db.WithContext(c).Model(&models.Proposal{}).
+		Where("status = ? AND expires_at <= ?", models.ProposalPending, now).
+		Update("status", models.ProposalExpired).Error; err != nil {
+		return helper.ErrDatabase
+	}
+
+	return nil
+}
+
+func NewProposalRepo(db *gorm.DB) ProposalRepo {
+	return &ProposalRepoImpl{
+		db: db,
+	}
+}
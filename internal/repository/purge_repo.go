@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// purgeBatch bounds how many accounts of each kind one run processes.
+const purgeBatch = 100
+
+// anonymizedName replaces the name of a purged account, so old trips and
+// reviews still read sensibly.
+const anonymizedName = "Pengguna dihapus"
+
+type PurgeRepo interface {
+	PurgeAccounts(c context.Context, deletedBefore time.Time, anonymize bool) (dto.PurgeReport, error)
+	PurgeRevokedTokens(c context.Context, expiredBefore time.Time) (int64, error)
+}
+
+type PurgeRepoImpl struct {
+	db *gorm.DB
+}
+
+// PurgeAccounts permanently removes drivers and passengers that were soft
+// deleted before deletedBefore. With anonymize the rows stay but lose every
+// personal field; otherwise they are deleted. Transactions are never deleted:
+// in delete mode they are detached from the account, so revenue totals keep
+// adding up. Each account is purged in its own transaction.
+func (a *PurgeRepoImpl) PurgeAccounts(c context.Context, deletedBefore time.Time, anonymize bool) (res dto.PurgeReport, err error) {
+	var drivers, passengers []string
+
+	if err := a.db.WithContext(c).Unscoped().Model(&models.DriverDetails{}).
+		Where("deleted_at < ? AND purged_at IS NULL", deletedBefore).
+		Limit(purgeBatch).Pluck("id", &drivers).Error; err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	if err := a.db.WithContext(c).Unscoped().Model(&models.PassengerDetails{}).
+		Where("deleted_at < ? AND purged_at IS NULL", deletedBefore).
+		Limit(purgeBatch).Pluck("id", &passengers).Error; err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	for _, id := range drivers {
+		if err := a.purge(c, id, "driver", anonymize); err != nil {
+			return res, dbError(err)
+		}
+		res.Drivers++
+	}
+
+	for _, id := range passengers {
+		if err := a.purge(c, id, "passenger", anonymize); err != nil {
+			return res, dbError(err)
+		}
+		res.Passengers++
+	}
+
+	return res, nil
+}
+
+// PurgeRevokedTokens deletes the revocations of tokens that expired before
+// expiredBefore, which no longer need to be refused, and returns how many.
+func (a *PurgeRepoImpl) PurgeRevokedTokens(c context.Context, expiredBefore time.Time) (int64, error) {
+	res := a.db.WithContext(c).Delete(&models.RevokedToken{}, "expires_at < ?", expiredBefore)
+	if res.Error != nil {
+		return 0, helper.ErrDatabase
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (a *PurgeRepoImpl) purge(c context.Context, id string, kind string, anonymize bool) error {
+	mode := "delete"
+	if anonymize {
+		mode = "anonymize"
+	}
+
+	return a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		tx = tx.Unscoped().Session(&gorm.Session{})
+
+		if err := tx.Where("user_id = ?", id).Delete(&models.BlockedAccount{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.ResetPassword{}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		switch {
+		case anonymize && kind == "driver":
+			err = anonymizeDriver(tx, id)
+		case anonymize:
+			err = anonymizePassenger(tx, id)
+		case kind == "driver":
+			err = deleteDriver(tx, id)
+		default:
+			err = deletePassenger(tx, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, kind+".purge", kind, id, nil, map[string]string{"mode": mode})
+	})
+}
+
+func anonymizeDriver(tx *gorm.DB, id string) error {
+	if err := tx.Model(&models.DriverDetails{}).Where("id = ?", id).Updates(map[string]any{
+		"name":            anonymizedName,
+		"phone_number":    "",
+		"license_number":  "",
+		"sim":             "",
+		"qris_data":       "",
+		"profile_picture": "",
+		"ktp":             "",
+		"purged_at":       time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+
+	return anonymizeUser(tx, id)
+}
+
+func anonymizePassenger(tx *gorm.DB, id string) error {
+	if err := tx.Model(&models.PassengerDetails{}).Where("id = ?", id).Updates(map[string]any{
+		"name":          anonymizedName,
+		"date_of_birth": nil,
+		"purged_at":     time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+
+	return anonymizeUser(tx, id)
+}
+
+// anonymizeUser keeps the users row for foreign keys but makes it impossible
+// to log in with. The email stays unique because it embeds the id.
+func anonymizeUser(tx *gorm.DB, id string) error {
+	return tx.Model(&models.User{}).Where("id = ?", id).Updates(map[string]any{
+		"email":    "deleted+" + id + "@invalid",
+		"password": "",
+	}).Error
+}
+
+func deleteDriver(tx *gorm.DB, id string) error {
+	if err := tx.Model(&models.Transaction{}).Where("driver_id = ?", id).Update("driver_id", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("driver_id = ?", id).Delete(&models.Review{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&models.DriverDetails{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&models.User{}, "id = ?", id).Error
+}
+
+func deletePassenger(tx *gorm.DB, id string) error {
+	if err := tx.Model(&models.Transaction{}).Where("passenger_id = ?", id).Update("passenger_id", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("passenger_id = ?", id).Delete(&models.Review{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&models.PassengerDetails{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&models.User{}, "id = ?", id).Error
+}
+
+func NewPurgeRepo(db *gorm.DB) PurgeRepo {
+	return &PurgeRepoImpl{
+		db: db,
+	}
+}
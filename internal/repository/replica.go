@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// reader is the session for queries that may be answered by a read replica:
+// lists, reports, search and the audit log. Once the request has written
+// something it stays on the primary, so an admin never reads a stale copy of
+// their own change. Without configured replicas it is just db.WithContext(c).
+func reader(c context.Context, db *gorm.DB) *gorm.DB {
+	tx := db.WithContext(c)
+	if helper.HasWritten(c) {
+		return tx
+	}
+
+	return tx.Clauses(dbresolver.Use(models.ReplicaResolver))
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// reportTrip is what a report is built from: Trips trips made in the same hour
+// with the same route and accounts, their summed Amount and Fare, and a time
+// within that hour. Purged accounts leave DriverID or PassengerID empty.
+type reportTrip struct {
+	RouteID     *uint
+	DriverID    *string
+	PassengerID *string
+	Trips       int
+	Amount      int
+	Fare        *int
+	CreatedAt   time.Time
+}
+
+// hourOf is the SQL expression truncating column to the hour, as text in
+// hourLayout. Trips are grouped by it before they reach a builder. MySQL
+// stores times in the local time zone, see models.DatabaseInit, while
+// Postgres would format them in the time zone of the session, so they are
+// turned to UTC first. Report time zones are a whole number of hours from
+// both, see service.ReportConfigFromEnv, so the trips of an hour fall into
+// one bucket.
+func hourOf(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return "DATE_FORMAT(" + column + ", '%Y-%m-%d %H:00:00')"
+	case "postgres":
+		return "to_char(" + column + " AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:00:00')"
+	default:
+		return "strftime('%Y-%m-%d %H:00:00', " + column + ")"
+	}
+}
+
+const hourLayout = "2006-01-02 15:04:05"
+
+// addHours passes the rows of a query grouped by hourOf, which selects the
+// hour as hour, to add. The first hour can start before w, so its trips are
+// dated at the start of w instead.
+func addHours(db *gorm.DB, rows *sql.Rows, w dto.ReportWindow, add func(t reportTrip)) error {
+	// MySQL hours are in the local time zone and the others in UTC, see
+	// hourOf.
+	loc := time.UTC
+	if db.Dialector.Name() == "mysql" {
+		loc = time.Local
+	}
+
+	for rows.Next() {
+		var row struct {
+			Trip reportTrip `gorm:"embedded"`
+			Hour string
+		}
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		hour, err := time.ParseInLocation(hourLayout, row.Hour, loc)
+		if err != nil {
+			return err
+		}
+
+		row.Trip.CreatedAt = hour
+		if hour.Before(w.From) {
+			row.Trip.CreatedAt = w.From
+		}
+		add(row.Trip)
+	}
+
+	return rows.Err()
+}
+
+// reportBuilder sums up the trips of a window. The database sums them up per
+// hour, and buckets are cut from the hours in Go rather than in SQL, because
+// truncating a time to a week or a month in a given time zone is spelled
+// differently by every database.
+type reportBuilder struct {
+	window  dto.ReportWindow
+	starts  []time.Time
+	series  []dto.ReportBucket
+	routes  map[uint]*dto.RoutesReport
+	common  dto.CommonReport
+	active  []map[string]bool
+	drivers map[string]bool
+	riders  map[string]bool
+	onRoute map[uint]map[string]bool
+}
+
+func newReportBuilder(w dto.ReportWindow) *reportBuilder {
+	b := &reportBuilder{
+		window:  w,
+		routes:  map[uint]*dto.RoutesReport{},
+		drivers: map[string]bool{},
+		riders:  map[string]bool{},
+		onRoute: map[uint]map[string]bool{},
+	}
+
+	for start := helper.BucketStart(w.From, w.Granularity); start.Before(w.To); start = helper.NextBucket(start, w.Granularity) {
+		b.starts = append(b.starts, start)
+		b.series = append(b.series, dto.ReportBucket{Start: start})
+		b.active = append(b.active, map[string]bool{})
+	}
+
+	return b
+}
+
+// add counts a trip, which has to be inside the window.
+func (b *reportBuilder) add(t reportTrip) {
+	i := sort.Search(len(b.starts), func(i int) bool { return b.starts[i].After(t.CreatedAt) }) - 1
+	if i < 0 {
+		return
+	}
+
+	bucket := &b.series[i]
+	bucket.Trips += t.Trips
+	bucket.Revenue += int64(t.Amount)
+	b.common.TotalTrip += t.Trips
+	b.common.TotalRevenue += int64(t.Amount)
+
+	// Drivers and passengers share the set of a bucket, so their IDs are
+	// kept apart by a prefix.
+	if t.DriverID != nil {
+		if !b.active[i]["d:"+*t.DriverID] {
+			b.active[i]["d:"+*t.DriverID] = true
+			bucket.ActiveDrivers++
+		}
+		b.drivers[*t.DriverID] = true
+	}
+	if t.PassengerID != nil {
+		if !b.active[i]["p:"+*t.PassengerID] {
+			b.active[i]["p:"+*t.PassengerID] = true
+			bucket.ActivePassengers++
+		}
+		b.riders[*t.PassengerID] = true
+	}
+
+	if t.RouteID == nil {
+		return
+	}
+
+	trip, ok := b.routes[*t.RouteID]
+	if !ok {
+		trip = &dto.RoutesReport{Route: fmt.Sprintf("Rute %d", *t.RouteID)}
+		b.routes[*t.RouteID] = trip
+		b.onRoute[*t.RouteID] = map[string]bool{}
+	}
+	trip.Total += t.Trips
+	trip.Revenue += int64(t.Amount)
+	if t.Fare != nil {
+		trip.FareRevenue += int64(*t.Fare)
+	}
+
+	active := b.onRoute[*t.RouteID]
+	if t.DriverID != nil && !active["d:"+*t.DriverID] {
+		active["d:"+*t.DriverID] = true
+		trip.ActiveDrivers++
+	}
+	if t.PassengerID != nil && !active["p:"+*t.PassengerID] {
+		active["p:"+*t.PassengerID] = true
+		trip.ActivePassengers++
+	}
+}
+
+// report returns what was added, with the account totals of accounts.
+func (b *reportBuilder) report(accounts dto.CommonReport) dto.Report {
+	common := b.common
+	common.TotalPassenger = accounts.TotalPassenger
+	common.TotalDriver = accounts.TotalDriver
+	common.ActiveDrivers = len(b.drivers)
+	common.ActivePassengers = len(b.riders)
+
+	ids := make([]uint, 0, len(b.routes))
+	for id := range b.routes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	trips := make([]dto.RoutesReport, 0, len(ids))
+	for _, id := range ids {
+		trips = append(trips, *b.routes[id])
+	}
+
+	return dto.Report{
+		From:        b.window.From,
+		To:          b.window.To,
+		Granularity: b.window.Granularity,
+		Common:      common,
+		Trips:       trips,
+		Series:      b.series,
+	}
+}
+
+// earningsReview is what a driver report is built from: Reviews reviews of a
+// driver and the sum of their stars.
+type earningsReview struct {
+	DriverID string
+	Reviews  int
+	Star     int
+}
+
+// earningsBuilder sums up the trips and reviews of the drivers in a window.
+// Trips and reviews of other drivers are left out.
+type earningsBuilder struct {
+	window  dto.ReportWindow
+	drivers map[string]*dto.DriverEarnings
+	days    map[string]map[time.Time]bool
+	stars   map[string]int
+}
+
+func newEarningsBuilder(w dto.ReportWindow, drivers []models.DriverDetails) *earningsBuilder {
+	b := &earningsBuilder{
+		window:  w,
+		drivers: map[string]*dto.DriverEarnings{},
+		days:    map[string]map[time.Time]bool{},
+		stars:   map[string]int{},
+	}
+
+	for _, d := range drivers {
+		b.drivers[d.ID] = &dto.DriverEarnings{DriverID: d.ID, Name: d.Name}
+		b.days[d.ID] = map[time.Time]bool{}
+	}
+
+	return b
+}
+
+func (b *earningsBuilder) addTrip(t reportTrip) {
+	if t.DriverID == nil {
+		return
+	}
+
+	driver, ok := b.drivers[*t.DriverID]
+	if !ok {
+		return
+	}
+
+	driver.Trips += t.Trips
+	driver.Revenue += int64(t.Amount)
+
+	// Days are cut in the time zone of the window, not the one the
+	// database hands the time back in.
+	day := helper.BucketStart(t.CreatedAt.In(b.window.From.Location()), "day")
+	if !b.days[*t.DriverID][day] {
+		b.days[*t.DriverID][day] = true
+		driver.ActiveDays++
+	}
+}
+
+func (b *earningsBuilder) addReview(r earningsReview) {
+	if driver, ok := b.drivers[r.DriverID]; ok {
+		driver.Reviews += r.Reviews
+		b.stars[r.DriverID] += r.Star
+	}
+}
+
+// earnings returns the drivers ordered by ID.
+func (b *earningsBuilder) earnings() []dto.DriverEarnings {
+	res := make([]dto.DriverEarnings, 0, len(b.drivers))
+	for id, driver := range b.drivers {
+		if driver.Trips > 0 {
+			driver.AverageFare = float64(driver.Revenue) / float64(driver.Trips)
+		}
+		if driver.Reviews > 0 {
+			driver.AverageRating = float64(b.stars[id]) / float64(driver.Reviews)
+		}
+		res = append(res, *driver)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].DriverID < res[j].DriverID })
+
+	return res
+}
+
+// demandBuilder counts the trips of a window by day of the week and hour of
+// the day, in the time zone of the window.
+type demandBuilder struct {
+	window dto.ReportWindow
+	all    dto.DemandMatrix
+	routes map[uint]*dto.RouteDemand
+}
+
+func newDemandBuilder(w dto.ReportWindow) *demandBuilder {
+	return &demandBuilder{
+		window: w,
+		routes: map[uint]*dto.RouteDemand{},
+	}
+}
+
+func (b *demandBuilder) add(t reportTrip) {
+	local := t.CreatedAt.In(b.window.From.Location())
+	// time.Weekday counts from Sunday, the rows from Monday.
+	day, hour := (int(local.Weekday())+6)%7, local.Hour()
+
+	b.all.Trips[day][hour] += t.Trips
+	b.all.Revenue[day][hour] += int64(t.Amount)
+
+	if t.RouteID == nil {
+		return
+	}
+
+	route, ok := b.routes[*t.RouteID]
+	if !ok {
+		route = &dto.RouteDemand{Route: fmt.Sprintf("Rute %d", *t.RouteID)}
+		b.routes[*t.RouteID] = route
+	}
+	route.Trips[day][hour] += t.Trips
+	route.Revenue[day][hour] += int64(t.Amount)
+}
+
+// demand returns what was added, with the routes ordered by ID.
+func (b *demandBuilder) demand() dto.Demand {
+	ids := make([]uint, 0, len(b.routes))
+	for id := range b.routes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	routes := make([]dto.RouteDemand, 0, len(ids))
+	for _, id := range ids {
+		route := *b.routes[id]
+		route.Peak = demandPeak(route.Trips)
+		routes = append(routes, route)
+	}
+
+	all := b.all
+	all.Peak = demandPeak(all.Trips)
+
+	return dto.Demand{
+		From:     b.window.From,
+		To:       b.window.To,
+		Timezone: b.window.From.Location().String(),
+		All:      all,
+		Routes:   routes,
+	}
+}
+
+func demandPeak(trips [7][24]int) *dto.DemandPeak {
+	var peak *dto.DemandPeak
+	for day := range trips {
+		for hour, n := range trips[day] {
+			if n > 0 && (peak == nil || n > peak.Trips) {
+				peak = &dto.DemandPeak{Weekday: day, Hour: hour, Trips: n}
+			}
+		}
+	}
+
+	return peak
+}
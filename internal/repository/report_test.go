@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestHourOf(t *testing.T) {
+	tests := []struct {
+		dialector gorm.Dialector
+		want      string
+	}{
+		{mysql.New(mysql.Config{}), "DATE_FORMAT(t.created_at, '%Y-%m-%d %H:00:00')"},
+		{postgres.New(postgres.Config{}), "to_char(t.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:00:00')"},
+		{sqlite.Open(""), "strftime('%Y-%m-%d %H:00:00', t.created_at)"},
+	}
+
+	for _, tt := range tests {
+		db := &gorm.DB{Config: &gorm.Config{Dialector: tt.dialector}}
+		if got := hourOf(db, "t.created_at"); got != tt.want {
+			t.Errorf("%s: hourOf = %q, want %q", tt.dialector.Name(), got, tt.want)
+		}
+	}
+}
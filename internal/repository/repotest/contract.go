@@ -0,0 +1,797 @@
+// Package repotest holds the contract every repository.DashboardRepo must
+// meet. Like testing/fstest it is a plain package, so the same checks can be
+// run from tests and tools against DashboardRepoImpl, MemoryDashboardRepo or
+// any later implementation.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+)
+
+// NewRepo returns an implementation holding exactly data.
+type NewRepo func(data repository.MemoryData) (repository.DashboardRepo, error)
+
+type check struct {
+	name string
+	run  func(c context.Context, repo repository.DashboardRepo) error
+}
+
+// dataCheck is a check that needs Fixture changed by data first.
+type dataCheck struct {
+	check
+	data func(d *repository.MemoryData)
+}
+
+// TestDashboardRepo runs every check against a fresh repository seeded with
+// Fixture and returns an error describing each check that failed.
+func TestDashboardRepo(newRepo NewRepo) error {
+	var errs []error
+
+	all := make([]dataCheck, 0, len(checks)+len(dataChecks))
+	for _, check := range checks {
+		all = append(all, dataCheck{check: check})
+	}
+	all = append(all, dataChecks...)
+
+	for _, check := range all {
+		data := Fixture()
+		if check.data != nil {
+			check.data(&data)
+		}
+
+		repo, err := newRepo(data)
+		if err != nil {
+			return fmt.Errorf("new repository: %w", err)
+		}
+
+		if err := check.run(context.Background(), repo); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+var checks = []check{
+	{"drivers are listed by id without deleted ones", func(c context.Context, repo repository.DashboardRepo) error {
+		return expectDrivers(c, repo, dto.ListQuery{}, "d1", "d2")
+	}},
+	{"drivers are filtered", func(c context.Context, repo repository.DashboardRepo) error {
+		if err := expectDrivers(c, repo, dto.ListQuery{Filters: map[string]string{"verified": "true"}}, "d1"); err != nil {
+			return err
+		}
+		if err := expectDrivers(c, repo, dto.ListQuery{Filters: map[string]string{"route_id": "1"}}, "d1"); err != nil {
+			return err
+		}
+		return expectDrivers(c, repo, dto.ListQuery{Filters: map[string]string{"deleted": "true"}}, "d3")
+	}},
+	{"drivers are sorted", func(c context.Context, repo repository.DashboardRepo) error {
+		return expectDrivers(c, repo, dto.ListQuery{Sort: "name", Order: "desc"}, "d2", "d1")
+	}},
+	{"drivers are paged with cursors", func(c context.Context, repo repository.DashboardRepo) error {
+		for _, sort := range []string{"id", "name"} {
+			var got []string
+			q := dto.ListQuery{Limit: 1, Sort: sort}
+
+			for {
+				res, page, err := repo.GetAllDrivers(c, q)
+				if err != nil {
+					return err
+				}
+				for _, d := range res {
+					got = append(got, d.ID)
+				}
+				if !page.HasMore {
+					break
+				}
+				if len(got) > 2 {
+					return fmt.Errorf("sort %s: paging does not end", sort)
+				}
+				q.Cursor = page.NextCursor
+			}
+
+			if !slices.Equal(got, []string{"d1", "d2"}) {
+				return fmt.Errorf("sort %s: got %v, want [d1 d2]", sort, got)
+			}
+		}
+		return nil
+	}},
+	{"invalid list queries are rejected", func(c context.Context, repo repository.DashboardRepo) error {
+		for _, q := range []dto.ListQuery{
+			{Sort: "route_id"},
+			{Sort: "password"},
+			{Filters: map[string]string{"password": "x"}},
+			{Filters: map[string]string{"verified": "maybe"}},
+			{Cursor: "not-a-cursor"},
+		} {
+			if _, _, err := repo.GetAllDrivers(c, q); !errors.Is(err, helper.ErrInvalidInput) {
+				return fmt.Errorf("query %+v: got %v, want %v", q, err, helper.ErrInvalidInput)
+			}
+		}
+		return nil
+	}},
+	{"passengers are listed and filtered", func(c context.Context, repo repository.DashboardRepo) error {
+		for _, tc := range []struct {
+			q    dto.ListQuery
+			want []string
+		}{
+			{dto.ListQuery{}, []string{"p1", "p2"}},
+			{dto.ListQuery{Filters: map[string]string{"age_from": "25"}}, []string{"p2"}},
+			{dto.ListQuery{Filters: map[string]string{"deleted": "true"}}, []string{"p3"}},
+		} {
+			res, _, err := repo.GetAllPassengers(c, tc.q)
+			if err != nil {
+				return err
+			}
+			if err := expectIDs(res, func(p models.Passengers) string { return p.ID }, tc.want); err != nil {
+				return fmt.Errorf("query %+v: %w", tc.q, err)
+			}
+		}
+		return nil
+	}},
+	{"drivers and passengers are found by id", func(c context.Context, repo repository.DashboardRepo) error {
+		d, err := repo.GetDriverByID(c, "d1")
+		if err != nil {
+			return err
+		}
+		if d.Name != "Andi" || d.Email != "andi@example.com" || !d.Verified {
+			return fmt.Errorf("driver d1: got %+v", d)
+		}
+
+		p, err := repo.GetPassengerByID(c, "p2")
+		if err != nil {
+			return err
+		}
+		if p.Name != "Eko" || p.Email != "eko@example.com" {
+			return fmt.Errorf("passenger p2: got %+v", p)
+		}
+
+		for _, id := range []string{"d3", "missing"} {
+			if _, err := repo.GetDriverByID(c, id); !errors.Is(err, helper.ErrNotFound) {
+				return fmt.Errorf("driver %s: got %v, want %v", id, err, helper.ErrNotFound)
+			}
+		}
+		for _, id := range []string{"p3", "missing"} {
+			if _, err := repo.GetPassengerByID(c, id); !errors.Is(err, helper.ErrNotFound) {
+				return fmt.Errorf("passenger %s: got %v, want %v", id, err, helper.ErrNotFound)
+			}
+		}
+		return nil
+	}},
+	{"reviews are listed newest first and found by id", func(c context.Context, repo repository.DashboardRepo) error {
+		res, _, err := repo.GetAllReview(c, dto.ListQuery{})
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(res, func(r models.Reviews) int { return r.ID }, []int{3, 2, 1}); err != nil {
+			return err
+		}
+
+		res, _, err = repo.GetAllReview(c, dto.ListQuery{Filters: map[string]string{"driver_id": "d1"}})
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(res, func(r models.Reviews) int { return r.ID }, []int{2, 1}); err != nil {
+			return fmt.Errorf("driver_id=d1: %w", err)
+		}
+
+		r, err := repo.GetReviewById(c, "2")
+		if err != nil {
+			return err
+		}
+		if r.PassengerName != "Eko" || r.DriverName != "Andi" || r.Star != 4 {
+			return fmt.Errorf("review 2: got %+v", r)
+		}
+
+		if _, err := repo.GetReviewById(c, "99"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("review 99: got %v, want %v", err, helper.ErrNotFound)
+		}
+		return nil
+	}},
+	{"trip histories are listed newest first", func(c context.Context, repo repository.DashboardRepo) error {
+		res, _, err := repo.GetAllTripHistories(c, dto.ListQuery{})
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(res, func(h models.Histories) int { return h.ID }, []int{1, 2, 4, 5, 3, 6}); err != nil {
+			return err
+		}
+		if res[0].Route != "Terminal - Kampus" || res[0].PassengerName != "Dewi" || res[0].DriverName != "Andi" {
+			return fmt.Errorf("trip 1: got %+v", res[0])
+		}
+		if res[1].Fare == nil || *res[1].Fare != 6000 {
+			return fmt.Errorf("trip 2: got fare %v, want 6000", res[1].Fare)
+		}
+		if res[3].Route != "" || res[3].Fare != nil {
+			return fmt.Errorf("trip without a route: got %+v", res[3])
+		}
+		if res[4].PassengerName != "Pengguna dihapus" || res[4].DriverName != "Andi" {
+			return fmt.Errorf("trip of a purged passenger: got %+v", res[4])
+		}
+
+		res, _, err = repo.GetAllTripHistories(c, dto.ListQuery{Filters: map[string]string{"route_id": "2"}})
+		if err != nil {
+			return err
+		}
+		return expectIDs(res, func(h models.Histories) int { return h.ID }, []int{2, 6})
+	}},
+	{"trips without a time are paged before the others", func(c context.Context, repo repository.DashboardRepo) error {
+		got, err := tripPages(c, repo, dto.ListQuery{Limit: 2, Sort: "created_at", Order: "asc"})
+		if err != nil {
+			return err
+		}
+		if want := []int{6, 3, 5, 4, 2, 1}; !slices.Equal(got, want) {
+			return fmt.Errorf("oldest first: got %v, want %v", got, want)
+		}
+
+		got, err = tripPages(c, repo, dto.ListQuery{Limit: 1})
+		if err != nil {
+			return err
+		}
+		if want := []int{1, 2, 4, 5, 3, 6}; !slices.Equal(got, want) {
+			return fmt.Errorf("newest first: got %v, want %v", got, want)
+		}
+		return nil
+	}},
+	{"trips stay on the route they were made on", func(c context.Context, repo repository.DashboardRepo) error {
+		res, _, err := repo.GetAllTripHistories(c, dto.ListQuery{Filters: map[string]string{"route_id": "1"}})
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(res, func(h models.Histories) int { return h.ID }, []int{1, 4, 3}); err != nil {
+			return err
+		}
+		if res[1].DriverName != "Budi" || res[1].Route != "Terminal - Kampus" {
+			return fmt.Errorf("trip 4: got %+v", res[1])
+		}
+
+		got, err := tripPages(c, repo, dto.ListQuery{Limit: 2, Sort: "route", Order: "asc"})
+		if err != nil {
+			return err
+		}
+		if want := []int{5, 2, 6, 1, 3, 4}; !slices.Equal(got, want) {
+			return fmt.Errorf("sorted by route: got %v, want %v", got, want)
+		}
+		return nil
+	}},
+	{"accounts are blocked once and unblocked", func(c context.Context, repo repository.DashboardRepo) error {
+		if _, err := repo.BlockAccount(c, models.BlockedAccount{UserID: "d1"}); err != nil {
+			return err
+		}
+		if _, err := repo.BlockAccount(c, models.BlockedAccount{UserID: "d1"}); !errors.Is(err, helper.ErrDuplicateEntry) {
+			return fmt.Errorf("blocking twice: got %v, want %v", err, helper.ErrDuplicateEntry)
+		}
+		if _, err := repo.BlockAccount(c, models.BlockedAccount{UserID: "missing"}); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("blocking a missing account: got %v, want %v", err, helper.ErrNotFound)
+		}
+
+		if blocked, err := repo.IsBlocked(c, "d1"); err != nil || !blocked {
+			return fmt.Errorf("IsBlocked(d1): got %v, %v", blocked, err)
+		}
+
+		res, _, err := repo.GetAllBlcokAccount(c, dto.ListQuery{})
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(res, func(b models.BlockDriver) string { return b.ID }, []string{"d1", "d2"}); err != nil {
+			return err
+		}
+
+		if _, err := repo.UnblockAccount(c, "d1"); err != nil {
+			return err
+		}
+		if _, err := repo.UnblockAccount(c, "d1"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("unblocking twice: got %v, want %v", err, helper.ErrNotFound)
+		}
+		if blocked, err := repo.IsBlocked(c, "d1"); err != nil || blocked {
+			return fmt.Errorf("IsBlocked(d1) after unblock: got %v, %v", blocked, err)
+		}
+		return nil
+	}},
+	{"drivers are verified", func(c context.Context, repo repository.DashboardRepo) error {
+		verified, err := repo.SetDriverStatusVerified(c, "d2", 0)
+		if err != nil {
+			return err
+		}
+		if verified.ID != "d2" || !verified.Verified || verified.Email != "budi@example.com" {
+			return fmt.Errorf("verified driver: got %+v", verified)
+		}
+		if d, err := repo.GetDriverByID(c, "d2"); err != nil || !d.Verified {
+			return fmt.Errorf("driver d2 after verify: got %+v, %v", d, err)
+		}
+		if _, err := repo.SetDriverStatusVerified(c, "d3", 0); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("verifying a deleted driver: got %v, want %v", err, helper.ErrNotFound)
+		}
+		return nil
+	}},
+	{"drivers are deleted and restored", func(c context.Context, repo repository.DashboardRepo) error {
+		report, err := repo.DeleteDriver(c, "d1", 0)
+		if err != nil {
+			return err
+		}
+		want := dto.DeletionReport{ID: "d1", Details: 1, Users: 1, ResetPasswords: 1}
+		if report != want {
+			return fmt.Errorf("deletion report: got %+v, want %+v", report, want)
+		}
+
+		if _, err := repo.GetDriverByID(c, "d1"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("deleted driver: got %v, want %v", err, helper.ErrNotFound)
+		}
+		if _, err := repo.DeleteDriver(c, "d1", 0); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("deleting twice: got %v, want %v", err, helper.ErrNotFound)
+		}
+
+		if _, err := repo.RestoreDriver(c, "d1"); err != nil {
+			return err
+		}
+		if _, err := repo.GetDriverByID(c, "d1"); err != nil {
+			return fmt.Errorf("restored driver: %w", err)
+		}
+		if _, err := repo.RestoreDriver(c, "d2"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("restoring a live driver: got %v, want %v", err, helper.ErrNotFound)
+		}
+		return nil
+	}},
+	{"a restored account is still blocked", func(c context.Context, repo repository.DashboardRepo) error {
+		if _, err := repo.DeleteDriver(c, "d2", 0); err != nil {
+			return err
+		}
+		if _, err := repo.RestoreDriver(c, "d2"); err != nil {
+			return err
+		}
+
+		if blocked, err := repo.IsBlocked(c, "d2"); err != nil || !blocked {
+			return fmt.Errorf("IsBlocked(d2) after restore: got %v, %v", blocked, err)
+		}
+		return nil
+	}},
+	{"passengers are deleted and restored", func(c context.Context, repo repository.DashboardRepo) error {
+		report, err := repo.DeleteUser(c, "p1")
+		if err != nil {
+			return err
+		}
+		want := dto.DeletionReport{ID: "p1", Details: 1, Users: 1}
+		if report != want {
+			return fmt.Errorf("deletion report: got %+v, want %+v", report, want)
+		}
+
+		if _, err := repo.GetPassengerByID(c, "p1"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("deleted passenger: got %v, want %v", err, helper.ErrNotFound)
+		}
+		if _, err := repo.RestoreUser(c, "p1"); err != nil {
+			return err
+		}
+		if _, err := repo.GetPassengerByID(c, "p1"); err != nil {
+			return fmt.Errorf("restored passenger: %w", err)
+		}
+		if _, err := repo.DeleteUser(c, "missing"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("deleting a missing passenger: got %v, want %v", err, helper.ErrNotFound)
+		}
+		return nil
+	}},
+	{"route versions guard against lost updates", func(c context.Context, repo repository.DashboardRepo) error {
+		route, err := repo.GetRouteByID(c, "1")
+		if err != nil {
+			return err
+		}
+		if route.Version != 1 {
+			return fmt.Errorf("fixture route version: got %d, want 1", route.Version)
+		}
+
+		edited, err := repo.EditAmountRoute(c, models.Route{Amount: 8000, Version: 1}, "1")
+		if err != nil {
+			return err
+		}
+		if edited.Version != 2 || edited.Amount != 8000 {
+			return fmt.Errorf("edited route: got %+v", edited)
+		}
+
+		if _, err := repo.EditAmountRoute(c, models.Route{Amount: 9000, Version: 1}, "1"); !errors.Is(err, helper.ErrVersionMismatch) {
+			return fmt.Errorf("editing a stale version: got %v, want %v", err, helper.ErrVersionMismatch)
+		}
+		if _, err := repo.DeleteRoute(c, "3", 2); !errors.Is(err, helper.ErrVersionMismatch) {
+			return fmt.Errorf("deleting a stale version: got %v, want %v", err, helper.ErrVersionMismatch)
+		}
+		if route, err := repo.GetRouteByID(c, "1"); err != nil || route.Amount != 8000 {
+			return fmt.Errorf("route 1 after stale edit: got %+v, %v", route, err)
+		}
+		if _, err := repo.GetRouteByID(c, "99"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("missing route: got %v, want %v", err, helper.ErrNotFound)
+		}
+		return nil
+	}},
+	{"driver versions guard against lost updates", func(c context.Context, repo repository.DashboardRepo) error {
+		verified, err := repo.SetDriverStatusVerified(c, "d2", 1)
+		if err != nil {
+			return err
+		}
+		if verified.Version != 2 {
+			return fmt.Errorf("verified driver version: got %d, want 2", verified.Version)
+		}
+
+		if _, err := repo.DeleteDriver(c, "d2", 1); !errors.Is(err, helper.ErrVersionMismatch) {
+			return fmt.Errorf("deleting a stale version: got %v, want %v", err, helper.ErrVersionMismatch)
+		}
+		if _, err := repo.DeleteDriver(c, "d2", 2); err != nil {
+			return err
+		}
+
+		if _, err := repo.RestoreDriver(c, "d2"); err != nil {
+			return err
+		}
+		if d, err := repo.GetDriverByID(c, "d2"); err != nil || d.Version != 4 {
+			return fmt.Errorf("restored driver: got %+v, %v, want version 4", d, err)
+		}
+		return nil
+	}},
+	{"routes are added, edited and deleted", func(c context.Context, repo repository.DashboardRepo) error {
+		added, err := repo.AddRoute(c, models.Route{RouteName: "Baru", Amount: 6000})
+		if err != nil {
+			return err
+		}
+		if added.ID == 0 || added.Version != 1 {
+			return fmt.Errorf("added route: got %+v", added)
+		}
+
+		edited, err := repo.EditAmountRoute(c, models.Route{Amount: 8000}, "1")
+		if err != nil {
+			return err
+		}
+		if edited.ID != 1 || edited.Amount != 8000 || edited.RouteName != "Terminal - Kampus" {
+			return fmt.Errorf("edited route: got %+v", edited)
+		}
+		if _, err := repo.EditAmountRoute(c, models.Route{Amount: 1}, "99"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("editing a missing route: got %v, want %v", err, helper.ErrNotFound)
+		}
+
+		if _, err := repo.DeleteRoute(c, "3", 0); err != nil {
+			return err
+		}
+		if _, err := repo.DeleteRoute(c, "3", 0); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("deleting twice: got %v, want %v", err, helper.ErrNotFound)
+		}
+		if _, err := repo.DeleteRoute(c, "1", 0); !errors.Is(err, helper.ErrInUse) {
+			return fmt.Errorf("deleting a route with drivers and trips: got %v, want %v", err, helper.ErrInUse)
+		}
+
+		routes, err := repo.GetRoutes(c)
+		if err != nil {
+			return err
+		}
+		ids := []uint{1, 2, added.ID}
+		got := make([]uint, 0, len(routes))
+		for _, r := range routes {
+			got = append(got, r.ID)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, ids) {
+			return fmt.Errorf("routes: got %v, want %v", got, ids)
+		}
+		return nil
+	}},
+	{"routes show the fare in effect now", func(c context.Context, repo repository.DashboardRepo) error {
+		routes, err := repo.GetRoutes(c)
+		if err != nil {
+			return err
+		}
+
+		got := make([]int, 0, len(routes))
+		for _, r := range routes {
+			got = append(got, r.Amount)
+		}
+		if want := []int{5000, 7000, 4000}; !slices.Equal(got, want) {
+			return fmt.Errorf("amounts: got %v, want %v", got, want)
+		}
+		return nil
+	}},
+	{"fares are scheduled, listed and cancelled", func(c context.Context, repo repository.DashboardRepo) error {
+		fares, err := repo.GetRouteFares(c, "1")
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(fares, func(f models.RouteFare) uint { return f.ID }, []uint{1, 2}); err != nil {
+			return fmt.Errorf("fixture fares: %w", err)
+		}
+		increase := fares[1].EffectiveFrom
+
+		from := time.Now().AddDate(0, 0, 10).Truncate(time.Second)
+		scheduled, err := repo.ScheduleRouteFare(c, "1", models.RouteFare{Amount: 5500, EffectiveFrom: from})
+		if err != nil {
+			return err
+		}
+		if scheduled.ID == 0 || scheduled.EffectiveTo == nil || !scheduled.EffectiveTo.Equal(increase) {
+			return fmt.Errorf("scheduled fare: got %+v, want it to end at %v", scheduled, increase)
+		}
+		if fares, err = repo.GetRouteFares(c, "1"); err != nil {
+			return err
+		}
+		if len(fares) != 3 || fares[0].EffectiveTo == nil || !fares[0].EffectiveTo.Equal(from) {
+			return fmt.Errorf("fares after scheduling: got %+v, want the current one to end at %v", fares, from)
+		}
+
+		if _, err := repo.ScheduleRouteFare(c, "1", models.RouteFare{Amount: 1, EffectiveFrom: from}); !errors.Is(err, helper.ErrFareExists) {
+			return fmt.Errorf("scheduling twice: got %v, want %v", err, helper.ErrFareExists)
+		}
+		if _, err := repo.ScheduleRouteFare(c, "99", models.RouteFare{Amount: 1, EffectiveFrom: from}); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("scheduling on a missing route: got %v, want %v", err, helper.ErrNotFound)
+		}
+
+		id := strconv.FormatUint(uint64(scheduled.ID), 10)
+		if _, err := repo.CancelRouteFare(c, "2", id); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("cancelling on another route: got %v, want %v", err, helper.ErrNotFound)
+		}
+		if _, err := repo.CancelRouteFare(c, "1", id); err != nil {
+			return err
+		}
+		if _, err := repo.CancelRouteFare(c, "1", "1"); !errors.Is(err, helper.ErrFareInEffect) {
+			return fmt.Errorf("cancelling the current fare: got %v, want %v", err, helper.ErrFareInEffect)
+		}
+
+		if fares, err = repo.GetRouteFares(c, "1"); err != nil {
+			return err
+		}
+		if len(fares) != 2 || fares[0].EffectiveTo == nil || !fares[0].EffectiveTo.Equal(increase) {
+			return fmt.Errorf("fares after cancelling: got %+v, want the current one to end at %v", fares, increase)
+		}
+		if _, err := repo.GetRouteFares(c, "99"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("fares of a missing route: got %v, want %v", err, helper.ErrNotFound)
+		}
+		return nil
+	}},
+	{"fares are applied to routes once they take effect", func(c context.Context, repo repository.DashboardRepo) error {
+		applied, err := repo.ApplyRouteFares(c, time.Now())
+		if err != nil {
+			return err
+		}
+		if len(applied) != 0 {
+			return fmt.Errorf("applied before the increase: got %+v", applied)
+		}
+
+		later := time.Now().AddDate(0, 0, 31)
+		if applied, err = repo.ApplyRouteFares(c, later); err != nil {
+			return err
+		}
+		if len(applied) != 1 || applied[0].ID != 1 || applied[0].Amount != 6000 || applied[0].Version != 2 {
+			return fmt.Errorf("applied after the increase: got %+v", applied)
+		}
+
+		if applied, err = repo.ApplyRouteFares(c, later); err != nil || len(applied) != 0 {
+			return fmt.Errorf("applied twice: got %+v, %v", applied, err)
+		}
+		return nil
+	}},
+	{"editing a route starts a new fare", func(c context.Context, repo repository.DashboardRepo) error {
+		if _, err := repo.EditAmountRoute(c, models.Route{Amount: 5500}, "1"); err != nil {
+			return err
+		}
+
+		fares, err := repo.GetRouteFares(c, "1")
+		if err != nil {
+			return err
+		}
+		got := make([]int, 0, len(fares))
+		for _, f := range fares {
+			got = append(got, f.Amount)
+		}
+		if want := []int{5000, 5500, 6000}; !slices.Equal(got, want) {
+			return fmt.Errorf("fare amounts: got %v, want %v", got, want)
+		}
+		if fares[1].EffectiveTo == nil || !fares[1].EffectiveTo.Equal(fares[2].EffectiveFrom) {
+			return fmt.Errorf("the new fare does not end at the scheduled one: got %+v", fares[1])
+		}
+
+		added, err := repo.AddRoute(c, models.Route{RouteName: "Baru", Amount: 3000})
+		if err != nil {
+			return err
+		}
+		if fares, err = repo.GetRouteFares(c, strconv.FormatUint(uint64(added.ID), 10)); err != nil || len(fares) != 1 || fares[0].Amount != 3000 {
+			return fmt.Errorf("fares of an added route: got %+v, %v", fares, err)
+		}
+		return nil
+	}},
+	{"the report covers its window only", func(c context.Context, repo repository.DashboardRepo) error {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		res, err := repo.Report(c, dto.ReportWindow{From: today.AddDate(0, 0, -30), To: today.AddDate(0, 0, 1), Granularity: "day"})
+		if err != nil {
+			return err
+		}
+
+		common := dto.CommonReport{TotalPassenger: 2, TotalDriver: 2, TotalTrip: 4, TotalRevenue: 21000, ActiveDrivers: 2, ActivePassengers: 2}
+		if res.Common != common {
+			return fmt.Errorf("common: got %+v, want %+v", res.Common, common)
+		}
+
+		trips := []dto.RoutesReport{
+			{Route: "Rute 1", Total: 2, Revenue: 10000, FareRevenue: 10000, ActiveDrivers: 2, ActivePassengers: 2},
+			{Route: "Rute 2", Total: 1, Revenue: 7000, FareRevenue: 6000, ActiveDrivers: 1, ActivePassengers: 1},
+		}
+		if !slices.Equal(res.Trips, trips) {
+			return fmt.Errorf("trips: got %+v, want %+v", res.Trips, trips)
+		}
+
+		if len(res.Series) != 31 {
+			return fmt.Errorf("series: got %d buckets, want 31", len(res.Series))
+		}
+		var sum dto.ReportBucket
+		for i, bucket := range res.Series {
+			if want := today.AddDate(0, 0, i-30); !bucket.Start.Equal(want) {
+				return fmt.Errorf("bucket %d: starts at %v, want %v", i, bucket.Start, want)
+			}
+			sum.Trips += bucket.Trips
+			sum.Revenue += bucket.Revenue
+		}
+		if sum.Trips != 4 || sum.Revenue != 21000 {
+			return fmt.Errorf("series: sums to %d trips and %d revenue, want 4 and 21000", sum.Trips, sum.Revenue)
+		}
+
+		res, err = repo.Report(c, dto.ReportWindow{From: today.AddDate(0, 0, -90), To: today.AddDate(0, 0, 1), Granularity: "month"})
+		if err != nil {
+			return err
+		}
+		if res.Common.TotalTrip != 5 || res.Trips[0].Total != 3 {
+			return fmt.Errorf("90 days: got %+v and %+v, want the trip older than a month", res.Common, res.Trips)
+		}
+		return nil
+	}},
+	{"driver earnings cover live drivers and the window only", func(c context.Context, repo repository.DashboardRepo) error {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		w := dto.ReportWindow{From: today.AddDate(0, 0, -30), To: today.AddDate(0, 0, 1), Granularity: "day"}
+
+		res, err := repo.DriverEarnings(c, w, "")
+		if err != nil {
+			return err
+		}
+
+		want := []dto.DriverEarnings{
+			{DriverID: "d1", Name: "Andi", Trips: 2, Revenue: 9000, AverageFare: 4500, ActiveDays: 2, Reviews: 2, AverageRating: 4.5},
+			{DriverID: "d2", Name: "Budi", Trips: 2, Revenue: 12000, AverageFare: 6000, ActiveDays: 2, Reviews: 1, AverageRating: 3},
+		}
+		if !slices.Equal(res, want) {
+			return fmt.Errorf("got %+v, want %+v", res, want)
+		}
+
+		res, err = repo.DriverEarnings(c, w, "d1")
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(res, want[:1]) {
+			return fmt.Errorf("d1: got %+v, want %+v", res, want[:1])
+		}
+
+		if _, err := repo.DriverEarnings(c, w, "d3"); !errors.Is(err, helper.ErrNotFound) {
+			return fmt.Errorf("deleted driver: got %v, want ErrNotFound", err)
+		}
+		return nil
+	}},
+	{"demand counts the trips of the window by weekday and hour", func(c context.Context, repo repository.DashboardRepo) error {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		res, err := repo.Demand(c, dto.ReportWindow{From: today.AddDate(0, 0, -30), To: today.AddDate(0, 0, 1), Granularity: "day"})
+		if err != nil {
+			return err
+		}
+
+		// Cells of a matrix, the trips and revenue they sum to.
+		sum := func(m dto.DemandMatrix) (cells, trips int, revenue int64) {
+			for day := range m.Trips {
+				for hour, n := range m.Trips[day] {
+					if n > 0 {
+						cells++
+					}
+					trips += n
+					revenue += m.Revenue[day][hour]
+				}
+			}
+			return cells, trips, revenue
+		}
+
+		if _, trips, revenue := sum(res.All); trips != 4 || revenue != 21000 {
+			return fmt.Errorf("all: got %d trips and %d revenue, want 4 and 21000", trips, revenue)
+		}
+		if res.All.Peak == nil || res.All.Peak.Trips != 1 {
+			return fmt.Errorf("all: got peak %+v, want one of a trip", res.All.Peak)
+		}
+
+		if len(res.Routes) != 2 || res.Routes[0].Route != "Rute 1" || res.Routes[1].Route != "Rute 2" {
+			return fmt.Errorf("routes: got %+v, want Rute 1 and Rute 2", res.Routes)
+		}
+		// The trips of route 1 are two days apart, so in two cells.
+		if cells, trips, revenue := sum(res.Routes[0].DemandMatrix); cells != 2 || trips != 2 || revenue != 10000 {
+			return fmt.Errorf("Rute 1: got %d trips and %d revenue in %d cells, want 2 and 10000 in 2", trips, revenue, cells)
+		}
+		if cells, trips, revenue := sum(res.Routes[1].DemandMatrix); cells != 1 || trips != 1 || revenue != 7000 {
+			return fmt.Errorf("Rute 2: got %d trips and %d revenue in %d cells, want 1 and 7000 in 1", trips, revenue, cells)
+		}
+
+		trip := time.Now().AddDate(0, 0, -2).UTC()
+		if peak := res.Routes[1].Peak; peak == nil || peak.Weekday != (int(trip.Weekday())+6)%7 {
+			return fmt.Errorf("Rute 2: got peak %+v, want one on the weekday of %v", peak, trip)
+		}
+		return nil
+	}},
+}
+
+var dataChecks = []dataCheck{
+	{check{"deleting a route keeps its trips without a route", func(c context.Context, repo repository.DashboardRepo) error {
+		if _, err := repo.DeleteRoute(c, "2", 0); err != nil {
+			return err
+		}
+
+		res, _, err := repo.GetAllTripHistories(c, dto.ListQuery{})
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(res, func(h models.Histories) int { return h.ID }, []int{1, 2, 4, 5, 3, 6}); err != nil {
+			return err
+		}
+		if res[1].Route != "" || res[1].Fare == nil || *res[1].Fare != 6000 {
+			return fmt.Errorf("trip 2: got %+v, want no route and a fare of 6000", res[1])
+		}
+
+		res, _, err = repo.GetAllTripHistories(c, dto.ListQuery{Filters: map[string]string{"route_id": "2"}})
+		if err != nil {
+			return err
+		}
+		return expectIDs(res, func(h models.Histories) int { return h.ID }, []int{})
+	}}, func(d *repository.MemoryData) {
+		// Only drivers keep a route from being deleted.
+		d.Drivers[1].RouteID = nil
+	}},
+}
+
+func expectDrivers(c context.Context, repo repository.DashboardRepo, q dto.ListQuery, want ...string) error {
+	res, _, err := repo.GetAllDrivers(c, q)
+	if err != nil {
+		return err
+	}
+
+	if err := expectIDs(res, func(d models.Drivers) string { return d.ID }, want); err != nil {
+		return fmt.Errorf("query %+v: %w", q, err)
+	}
+
+	return nil
+}
+
+// tripPages follows the cursors of q to the last page and returns the IDs of
+// the trips in the order they were listed.
+func tripPages(c context.Context, repo repository.DashboardRepo, q dto.ListQuery) ([]int, error) {
+	var got []int
+	for {
+		res, page, err := repo.GetAllTripHistories(c, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range res {
+			got = append(got, h.ID)
+		}
+		if !page.HasMore {
+			return got, nil
+		}
+		if len(got) > len(Fixture().Transactions) {
+			return nil, fmt.Errorf("paging by %s does not end", q.Sort)
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func expectIDs[T any, K comparable](rows []T, id func(T) K, want []K) error {
+	got := make([]K, 0, len(rows))
+	for _, row := range rows {
+		got = append(got, id(row))
+	}
+
+	if !slices.Equal(got, want) {
+		return fmt.Errorf("got %v, want %v", got, want)
+	}
+
+	return nil
+}
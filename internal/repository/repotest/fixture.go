@@ -0,0 +1,148 @@
+package repotest
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Fixture returns the data every check of TestDashboardRepo starts from:
+// three routes, of which route 3 has no drivers and no fare history, route 1
+// has a fare increase scheduled in 30 days and route 2 went up a day ago;
+// drivers d1 and d2 and the
+// deleted driver d3; passengers p1 and p2 and the deleted passenger p3; three
+// reviews; and six trips: one older than a month whose passenger has been
+// purged, one d2 made while still on route 1, one made without a route and
+// one without a time. d2 is blocked and d1 has a pending
+// password reset.
+func Fixture() repository.MemoryData {
+	now := time.Now().Truncate(time.Second)
+	deleted := gorm.DeletedAt{Time: now.AddDate(0, 0, -3), Valid: true}
+	route1, route2 := uint(1), uint(2)
+	fare := func(amount int) *int { return &amount }
+
+	at := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	// Fare boundaries are stored in UTC, see fareTime.
+	fareAt := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days).UTC()
+		return &t
+	}
+
+	return repository.MemoryData{
+		Routes: []models.Route{
+			{ID: 1, RouteName: "Terminal - Kampus", Amount: 5000},
+			{ID: 2, RouteName: "Pasar - Pelabuhan", Amount: 7000},
+			{ID: 3, RouteName: "Cadangan", Amount: 4000},
+		},
+		RouteFares: []models.RouteFare{
+			{ID: 1, RouteID: 1, Amount: 5000, EffectiveFrom: *fareAt(365), EffectiveTo: fareAt(-30)},
+			{ID: 2, RouteID: 1, Amount: 6000, EffectiveFrom: *fareAt(-30)},
+			{ID: 3, RouteID: 2, Amount: 6000, EffectiveFrom: *fareAt(365), EffectiveTo: fareAt(1)},
+			{ID: 4, RouteID: 2, Amount: 7000, EffectiveFrom: *fareAt(1)},
+		},
+		Users: []models.User{
+			{ID: "d1", Email: "andi@example.com", Password: "hash", Role: "driver"},
+			{ID: "d2", Email: "budi@example.com", Password: "hash", Role: "driver"},
+			{ID: "d3", Email: "citra@example.com", Role: "driver", DeletedAt: deleted},
+			{ID: "p1", Email: "dewi@example.com", Password: "hash", Role: "user"},
+			{ID: "p2", Email: "eko@example.com", Password: "hash", Role: "user"},
+			{ID: "p3", Email: "fitri@example.com", Role: "user", DeletedAt: deleted},
+		},
+		Drivers: []models.DriverDetails{
+			{ID: "d1", Name: "Andi", PhoneNumber: "0811", RouteID: &route1, Verified: true, Status: "on"},
+			{ID: "d2", Name: "Budi", PhoneNumber: "0812", RouteID: &route2, Status: "off"},
+			{ID: "d3", Name: "Citra", PhoneNumber: "0813", RouteID: &route1, DeletedAt: deleted},
+		},
+		Passengers: []models.PassengerDetails{
+			{ID: "p1", Name: "Dewi", DateOfBirth: time.Date(2004, 1, 2, 0, 0, 0, 0, time.UTC), Age: 20},
+			{ID: "p2", Name: "Eko", DateOfBirth: time.Date(1994, 5, 6, 0, 0, 0, 0, time.UTC), Age: 30},
+			{ID: "p3", Name: "Fitri", DateOfBirth: time.Date(1999, 7, 8, 0, 0, 0, 0, time.UTC), Age: 25, DeletedAt: deleted},
+		},
+		Reviews: []models.Review{
+			{ID: 1, PassengerID: "p1", DriverID: "d1", Comment: "Ramah", Star: 5, CreatedAt: now},
+			{ID: 2, PassengerID: "p2", DriverID: "d1", Comment: "Tepat waktu", Star: 4, CreatedAt: now},
+			{ID: 3, PassengerID: "p1", DriverID: "d2", Comment: "Biasa saja", Star: 3, CreatedAt: now},
+		},
+		Transactions: []models.Transaction{
+			{ID: 1, PassengerID: "p1", DriverID: "d1", RouteID: &route1, Amount: 5000, Fare: fare(5000), CreatedAt: at(1)},
+			{ID: 2, PassengerID: "p2", DriverID: "d2", RouteID: &route2, Amount: 7000, Fare: fare(6000), CreatedAt: at(2)},
+			{ID: 3, DriverID: "d1", RouteID: &route1, Amount: 5000, Fare: fare(5000), CreatedAt: at(60)},
+			{ID: 4, PassengerID: "p2", DriverID: "d2", RouteID: &route1, Amount: 5000, Fare: fare(5000), CreatedAt: at(3)},
+			{ID: 5, PassengerID: "p2", DriverID: "d1", Amount: 4000, CreatedAt: at(5)},
+			{ID: 6, PassengerID: "p1", DriverID: "d2", RouteID: &route2, Amount: 7000, Fare: fare(7000)},
+		},
+		BlockedAccounts: []models.BlockedAccount{
+			{ID: 1, UserID: "d2"},
+		},
+		ResetPasswords: []models.ResetPassword{
+			{ID: 1, UserID: "d1", Code: "123456"},
+		},
+	}
+}
+
+// Seed inserts data into a migrated database, so DashboardRepoImpl can be
+// checked against the same data as MemoryDashboardRepo. An empty account ID
+// of a trip is stored as NULL, which is what purging the account leaves, and
+// so are a nil CreatedAt, RouteID and Fare, which the column default and the
+// transactions_snapshot trigger would fill in otherwise.
+func Seed(db *gorm.DB, data repository.MemoryData) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, rows := range []any{
+			&data.Routes,
+			&data.RouteFares,
+			&data.Users,
+			&data.Drivers,
+			&data.Passengers,
+			&data.Reviews,
+			&data.BlockedAccounts,
+			&data.ResetPasswords,
+		} {
+			if reflect.ValueOf(rows).Elem().Len() == 0 {
+				continue
+			}
+
+			if err := tx.Omit(clause.Associations).Create(rows).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, t := range data.Transactions {
+			omit := []string{clause.Associations}
+			if t.PassengerID == "" {
+				omit = append(omit, "PassengerID")
+			}
+			if t.DriverID == "" {
+				omit = append(omit, "DriverID")
+			}
+
+			unset := map[string]any{}
+			if t.CreatedAt == nil {
+				unset["created_at"] = gorm.Expr("NULL")
+			}
+			if t.RouteID == nil {
+				unset["route_id"] = gorm.Expr("NULL")
+			}
+			if t.Fare == nil {
+				unset["fare"] = gorm.Expr("NULL")
+			}
+
+			if err := tx.Omit(omit...).Create(&t).Error; err != nil {
+				return err
+			}
+			if len(unset) > 0 {
+				if err := tx.Model(&models.Transaction{}).Where("id = ?", t.ID).UpdateColumns(unset).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revocationTTL bounds how long a revocation made on another instance can go
+// unnoticed by this one.
+const revocationTTL = 30 * time.Second
+
+type RevocationRepo interface {
+	RevokeToken(c context.Context, jti string, expiresAt time.Time) error
+	RevokeSubject(c context.Context, subject string, before time.Time) error
+	IsRevoked(c context.Context, jti string, subject string, issuedAt time.Time) (bool, error)
+}
+
+// RevocationRepoImpl keeps every live revocation in memory and reloads the
+// whole list from the database once it is older than revocationTTL. Only one
+// request reloads at a time; the others that find the list stale wait for it.
+// Expired token revocations are deleted by PurgeRepo.PurgeRevokedTokens.
+type RevocationRepoImpl struct {
+	db *gorm.DB
+
+	reloading sync.Mutex
+
+	mu       sync.RWMutex
+	tokens   map[string]time.Time
+	subjects map[string]time.Time
+	loadedAt time.Time
+}
+
+func (a *RevocationRepoImpl) RevokeToken(c context.Context, jti string, expiresAt time.Time) error {
+	data := models.RevokedToken{
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+	}
+
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&data).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "token.revoke", "token", jti, nil, map[string]time.Time{"expires_at": expiresAt})
+	}); err != nil {
+		return helper.ErrDatabase
+	}
+
+	a.mu.Lock()
+	a.tokens[jti] = expiresAt
+	a.mu.Unlock()
+
+	return nil
+}
+
+func (a *RevocationRepoImpl) RevokeSubject(c context.Context, subject string, before time.Time) error {
+	data := models.RevokedSubject{
+		Subject:       subject,
+		RevokedBefore: before,
+	}
+
+	if err := a.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subject"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revoked_before"}),
+		}).Create(&data).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(c, tx, "session.revoke", "admin", subject, nil, map[string]time.Time{"revoked_before": before})
+	}); err != nil {
+		return helper.ErrDatabase
+	}
+
+	a.mu.Lock()
+	a.subjects[subject] = before
+	a.mu.Unlock()
+
+	return nil
+}
+
+// IsRevoked reports whether the token was revoked by its jti, or was issued
+// before its subject's sessions were revoked. A token without iat counts as
+// issued at the beginning of time.
+func (a *RevocationRepoImpl) IsRevoked(c context.Context, jti string, subject string, issuedAt time.Time) (bool, error) {
+	if a.stale() {
+		if err := a.reload(c); err != nil {
+			return false, err
+		}
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if jti != "" {
+		if _, ok := a.tokens[jti]; ok {
+			return true, nil
+		}
+	}
+
+	if before, ok := a.subjects[subject]; ok && !issuedAt.After(before) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (a *RevocationRepoImpl) stale() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return time.Since(a.loadedAt) > revocationTTL
+}
+
+func (a *RevocationRepoImpl) reload(c context.Context) error {
+	a.reloading.Lock()
+	defer a.reloading.Unlock()
+
+	// Another request may have reloaded while this one waited.
+	if !a.stale() {
+		return nil
+	}
+
+	var tokens []models.RevokedToken
+	var subjects []models.RevokedSubject
+	now := time.Now()
+
+	if err := a.db.WithContext(c).Find(&tokens, "expires_at >= ?", now).Error; err != nil {
+		return helper.ErrDatabase
+	}
+
+	if err := a.db.WithContext(c).Find(&subjects).Error; err != nil {
+		return helper.ErrDatabase
+	}
+
+	tokenMap := make(map[string]time.Time, len(tokens))
+	for _, t := range tokens {
+		tokenMap[t.JTI] = t.ExpiresAt
+	}
+
+	subjectMap := make(map[string]time.Time, len(subjects))
+	for _, s := range subjects {
+		subjectMap[s.Subject] = s.RevokedBefore
+	}
+
+	a.mu.Lock()
+	a.tokens = tokenMap
+	a.subjects = subjectMap
+	a.loadedAt = now
+	a.mu.Unlock()
+
+	return nil
+}
+
+func NewRevocationRepo(db *gorm.DB) RevocationRepo {
+	return &RevocationRepoImpl{
+		db:       db,
+		tokens:   map[string]time.Time{},
+		subjects: map[string]time.Time{},
+	}
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// minFullTextWord is InnoDB's default innodb_ft_min_token_size. Shorter words
+// are not indexed, so queries containing one are answered with LIKE.
+const minFullTextWord = 3
+
+// searchEntity describes how one entity type is searched. Text columns have a
+// FULLTEXT index on MySQL; Plain columns hold identifiers such as phone
+// numbers, which are always matched with LIKE so partial numbers are found.
+type searchEntity struct {
+	Table  string
+	Joins  []string
+	Where  string
+	ID     string
+	Title  string
+	Detail string
+	Text   []string
+	Plain  []string
+}
+
+var searchEntities = map[string]searchEntity{
+	"driver": {
+		Table:  "driver_details as d",
+		Where:  "d.deleted_at IS NULL",
+		ID:     "d.id",
+		Title:  "d.name",
+		Detail: "d.phone_number",
+		Text:   []string{"d.name"},
+		Plain:  []string{"d.phone_number", "d.license_number", "d.sim"},
+	},
+	"passenger": {
+		Table:  "passenger_details as p",
+		Joins:  []string{"JOIN users u ON u.id = p.id"},
+		Where:  "p.deleted_at IS NULL",
+		ID:     "p.id",
+		Title:  "p.name",
+		Detail: "u.email",
+		Text:   []string{"p.name", "u.email"},
+	},
+	"route": {
+		Table:  "routes as r",
+		ID:     "r.id",
+		Title:  "r.route_name",
+		Detail: "r.amount",
+		Text:   []string{"r.route_name"},
+	},
+	"review": {
+		Table:  "reviews as rv",
+		Joins:  []string{"JOIN driver_details d ON d.id = rv.driver_id"},
+		ID:     "rv.id",
+		Title:  "rv.comment",
+		Detail: "d.name",
+		Text:   []string{"rv.comment"},
+	},
+}
+
+type SearchRepo interface {
+	Search(c context.Context, entity string, q string, limit int) ([]dto.SearchHit, error)
+}
+
+type SearchRepoImpl struct {
+	db *gorm.DB
+
+	once     sync.Once
+	fullText bool
+}
+
+func (a *SearchRepoImpl) Search(c context.Context, entity string, q string, limit int) (res []dto.SearchHit, err error) {
+	spec, ok := searchEntities[entity]
+	if !ok {
+		return res, helper.ErrInvalidInput
+	}
+
+	a.once.Do(func() {
+		a.fullText = models.HasFullTextSearch(a.db.WithContext(c))
+	})
+
+	var (
+		scores []string
+		args   []any
+	)
+
+	like := spec.Plain
+	if against, ok := fullTextQuery(q); a.fullText && ok {
+		for _, column := range spec.Text {
+			scores = append(scores, "MATCH("+column+") AGAINST (? IN BOOLEAN MODE)")
+			args = append(args, against)
+		}
+	} else {
+		like = append(append([]string{}, spec.Text...), spec.Plain...)
+	}
+
+	lower := strings.ToLower(q)
+	escaped := escapeLike(lower)
+	for _, column := range like {
+		scores = append(scores, "CASE WHEN LOWER("+column+") = ? THEN 3 WHEN LOWER("+column+") LIKE ? ESCAPE '!' THEN 2 WHEN LOWER("+column+") LIKE ? ESCAPE '!' THEN 1 ELSE 0 END")
+		args = append(args, lower, escaped+"%", "%"+escaped+"%")
+	}
+
+	score := "(" + strings.Join(scores, " + ") + ")"
+
+	query := reader(c, a.db).Table(spec.Table).
+		Select("'"+entity+"' AS type, "+spec.ID+" AS id, "+spec.Title+" AS title, "+spec.Detail+" AS detail, "+score+" AS score", args...)
+	for _, join := range spec.Joins {
+		query = query.Joins(join)
+	}
+	if spec.Where != "" {
+		query = query.Where(spec.Where)
+	}
+
+	if err := query.Where(score+" > 0", args...).
+		Order("score desc").
+		Order(spec.ID).
+		Limit(limit).
+		Scan(&res).Error; err != nil {
+		return res, helper.ErrDatabase
+	}
+
+	return res, nil
+}
+
+// fullTextQuery turns q into a boolean mode query that requires every word
+// as a prefix. It reports false when a word is too short to be indexed.
+func fullTextQuery(q string) (string, bool) {
+	words := strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "", false
+	}
+
+	for i, word := range words {
+		if len([]rune(word)) < minFullTextWord {
+			return "", false
+		}
+		words[i] = "+" + word + "*"
+	}
+
+	return strings.Join(words, " "), true
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+func NewSearchRepo(db *gorm.DB) SearchRepo {
+	return &SearchRepoImpl{
+		db: db,
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+)
+
+type AdminService interface {
+	GetRoles(c context.Context) (res []models.Role, err *helper.ErrorStruct)
+	SetAdminRole(c context.Context, adminID string, data dto.SetRole) (res models.Admin, err *helper.ErrorStruct)
+	Logout(c context.Context, jti string, adminID string, expiresAt time.Time) (res string, err *helper.ErrorStruct)
+	RevokeSessions(c context.Context, adminID string) (res string, err *helper.ErrorStruct)
+}
+
+type AdminServiceImpl struct {
+	AdminRepo      repository.AdminRepo
+	RevocationRepo repository.RevocationRepo
+}
+
+// Logout revokes the current token. Tokens without a jti cannot be told
+// apart, so every session of the admin is revoked instead.
+func (a *AdminServiceImpl) Logout(c context.Context, jti string, adminID string, expiresAt time.Time) (res string, err *helper.ErrorStruct) {
+	if jti == "" {
+		return a.RevokeSessions(c, adminID)
+	}
+
+	if errRepo := a.RevocationRepo.RevokeToken(c, jti, expiresAt); errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return "Berhasil logout", nil
+}
+
+func (a *AdminServiceImpl) RevokeSessions(c context.Context, adminID string) (res string, err *helper.ErrorStruct) {
+	// Truncate to whole seconds because iat has no sub-second precision.
+	before := time.Now().Truncate(time.Second)
+
+	if errRepo := a.RevocationRepo.RevokeSubject(c, adminID, before); errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return "Berhasil mencabut semua sesi admin", nil
+}
+
+func (a *AdminServiceImpl) GetRoles(c context.Context) (res []models.Role, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.AdminRepo.GetRoles(c)
+
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, nil
+}
+
+func (a *AdminServiceImpl) SetAdminRole(c context.Context, adminID string, data dto.SetRole) (res models.Admin, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	resRepo, errRepo := a.AdminRepo.SetAdminRole(c, adminID, data.Role)
+
+	if errRepo != nil {
+		var code int
+		switch {
+		case errors.Is(errRepo, helper.ErrInvalidInput):
+			code = http.StatusBadRequest
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Code: code,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, nil
+}
+
+func NewAdminService(adminRepo repository.AdminRepo, revocationRepo repository.RevocationRepo) AdminService {
+	return &AdminServiceImpl{
+		AdminRepo:      adminRepo,
+		RevocationRepo: revocationRepo,
+	}
+}
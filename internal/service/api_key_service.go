@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ApiKeyService interface {
+	CreateApiKey(c context.Context, createdBy string, data dto.CreateApiKey) (res dto.ApiKeyCreated, err *helper.ErrorStruct)
+	GetApiKeys(c context.Context) (res []models.ApiKey, err *helper.ErrorStruct)
+	RevokeApiKey(c context.Context, id string) (res models.ApiKey, err *helper.ErrorStruct)
+	RotateApiKey(c context.Context, id string) (res dto.ApiKeyCreated, err *helper.ErrorStruct)
+}
+
+type ApiKeyServiceImpl struct {
+	ApiKeyRepo repository.ApiKeyRepo
+}
+
+func (a *ApiKeyServiceImpl) CreateApiKey(c context.Context, createdBy string, data dto.CreateApiKey) (res dto.ApiKeyCreated, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	for _, scope := range data.Scopes {
+		if !slices.Contains(models.AllPermissions, scope) || slices.Contains(models.AdminOnlyPermissions, scope) {
+			return res, &helper.ErrorStruct{
+				Code: http.StatusBadRequest,
+				Err:  fmt.Errorf("%w: unknown scope %q", helper.ErrInvalidInput, scope),
+			}
+		}
+	}
+
+	if data.ExpiresAt != nil && data.ExpiresAt.Before(time.Now()) {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  fmt.Errorf("%w: expires_at is in the past", helper.ErrInvalidInput),
+		}
+	}
+
+	key, prefix, hash, errGen := helper.GenerateApiKey()
+	if errGen != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  helper.ErrInternal,
+		}
+	}
+
+	resRepo, errRepo := a.ApiKeyRepo.CreateApiKey(c, models.ApiKey{
+		ID:        uuid.NewString(),
+		Name:      data.Name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    data.Scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: data.ExpiresAt,
+	})
+
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return dto.ApiKeyCreated{ApiKey: resRepo, Key: key}, nil
+}
+
+func (a *ApiKeyServiceImpl) GetApiKeys(c context.Context) (res []models.ApiKey, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.ApiKeyRepo.GetApiKeys(c)
+
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, nil
+}
+
+func (a *ApiKeyServiceImpl) RevokeApiKey(c context.Context, id string) (res models.ApiKey, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.ApiKeyRepo.RevokeApiKey(c, id)
+
+	if errRepo != nil {
+		var code int
+		switch {
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Code: code,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, nil
+}
+
+// RotateApiKey replaces the secret of a key while keeping its id, name,
+// scopes and expiry. The old secret stops working immediately.
+func (a *ApiKeyServiceImpl) RotateApiKey(c context.Context, id string) (res dto.ApiKeyCreated, err *helper.ErrorStruct) {
+	key, prefix, hash, errGen := helper.GenerateApiKey()
+	if errGen != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  helper.ErrInternal,
+		}
+	}
+
+	resRepo, errRepo := a.ApiKeyRepo.RotateApiKey(c, id, prefix, hash)
+
+	if errRepo != nil {
+		var code int
+		switch {
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Code: code,
+			Err:  errRepo,
+		}
+	}
+
+	return dto.ApiKeyCreated{ApiKey: resRepo, Key: key}, nil
+}
+
+func NewApiKeyService(apiKeyRepo repository.ApiKeyRepo) ApiKeyService {
+	return &ApiKeyServiceImpl{
+		ApiKeyRepo: apiKeyRepo,
+	}
+}
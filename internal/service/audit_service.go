@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+)
+
+type AuditService interface {
+	GetAuditLogs(c context.Context, q dto.AuditQuery) (res []models.AuditLog, total int64, err *helper.ErrorStruct)
+}
+
+type AuditServiceImpl struct {
+	AuditRepo repository.AuditRepo
+}
+
+func (a *AuditServiceImpl) GetAuditLogs(c context.Context, q dto.AuditQuery) (res []models.AuditLog, total int64, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, total, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	if q.Page == 0 {
+		q.Page = 1
+	}
+
+	if q.Limit == 0 {
+		q.Limit = 50
+	}
+
+	resRepo, total, errRepo := a.AuditRepo.GetAuditLogs(c, q)
+
+	if errRepo != nil {
+		return res, total, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, total, nil
+}
+
+func NewAuditService(auditRepo repository.AuditRepo) AuditService {
+	return &AuditServiceImpl{
+		AuditRepo: auditRepo,
+	}
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/cache"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// CacheConfig picks the store dashboard results are cached in and how long
+// each kind of result is kept. A nil Store turns the cache off.
+type CacheConfig struct {
+	Store      cache.Store
+	RoutesTTL  time.Duration
+	ReportsTTL time.Duration
+	DriversTTL time.Duration
+}
+
+// CacheConfigFromEnv reads CACHE_BACKEND ("lru", "redis" or "off"),
+// CACHE_SIZE (entries kept by the LRU, default 1000), REDIS_URL for the redis
+// backend and the TTLs CACHE_ROUTES_TTL, CACHE_REPORTS_TTL (default 1m) and
+// CACHE_DRIVERS_TTL (default 30s). The backend is redis when REDIS_URL is set
+// and lru otherwise.
+//
+// The LRU is private to one instance: a mutation on one replica leaves the
+// others serving what they cached until it expires. Run it only on a single
+// instance, or live with results up to a TTL old; routes, whose fares drivers
+// charge from, are kept 10s by default with it and 5m with redis.
+func CacheConfigFromEnv() (cfg CacheConfig, err error) {
+	backend := os.Getenv("CACHE_BACKEND")
+	if backend == "" {
+		backend = "lru"
+		if os.Getenv("REDIS_URL") != "" {
+			backend = "redis"
+		}
+	}
+
+	routesTTL := 5 * time.Minute
+	switch backend {
+	case "lru":
+		routesTTL = 10 * time.Second
+
+		size := 1000
+		if v := os.Getenv("CACHE_SIZE"); v != "" {
+			if size, err = strconv.Atoi(v); err != nil || size < 1 {
+				return cfg, fmt.Errorf("CACHE_SIZE: invalid number of entries %q", v)
+			}
+		}
+		cfg.Store = cache.NewLRU(size)
+	case "redis":
+		opts, err := redis.ParseURL(os.Getenv("REDIS_URL"))
+		if err != nil {
+			return cfg, fmt.Errorf("REDIS_URL: %w", err)
+		}
+		// A cache that is down should cost a request little, so unless
+		// REDIS_URL says otherwise give up long before the go-redis defaults.
+		for _, timeout := range []*time.Duration{&opts.DialTimeout, &opts.ReadTimeout, &opts.WriteTimeout} {
+			if *timeout == 0 {
+				*timeout = 500 * time.Millisecond
+			}
+		}
+		cfg.Store = cache.NewRedis(redis.NewClient(opts), "mikronet:dashboard:")
+	case "off":
+	default:
+		return cfg, fmt.Errorf("CACHE_BACKEND: unknown backend %q", backend)
+	}
+
+	for _, ttl := range []struct {
+		name  string
+		value *time.Duration
+		def   time.Duration
+	}{
+		{"CACHE_ROUTES_TTL", &cfg.RoutesTTL, routesTTL},
+		{"CACHE_REPORTS_TTL", &cfg.ReportsTTL, time.Minute},
+		{"CACHE_DRIVERS_TTL", &cfg.DriversTTL, 30 * time.Second},
+	} {
+		*ttl.value = ttl.def
+		if v := os.Getenv(ttl.name); v != "" {
+			if *ttl.value, err = time.ParseDuration(v); err != nil || *ttl.value <= 0 {
+				return cfg, fmt.Errorf("%s: invalid duration %q", ttl.name, v)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// Groups of cached results that are invalidated together.
+const (
+	cacheRoutes  = "routes"
+	cacheReports = "reports"
+	cacheDrivers = "drivers"
+)
+
+type cacheCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// DashboardCache is a DashboardService that keeps the results of GetRoutes,
+// Report, DriverReport, Demand and GetAllDrivers in a cache.Store and passes
+// every other call through.
+//
+// Every group of results has a generation in the store, and cache keys
+// include it. A mutation that changes a group replaces its generation, which
+// orphans the old entries until they expire. This works the same for a
+// private LRU and a Redis shared by several instances.
+type DashboardCache struct {
+	DashboardService
+	Config CacheConfig
+
+	methods       map[string]*cacheCounters
+	invalidations map[string]*atomic.Int64
+}
+
+func (a *DashboardCache) GetRoutes(c context.Context) (res []models.Route, err *helper.ErrorStruct) {
+	return cached(a, c, "GetRoutes", cacheRoutes, a.Config.RoutesTTL, nil, func() ([]models.Route, *helper.ErrorStruct) {
+		return a.DashboardService.GetRoutes(c)
+	})
+}
+
+func (a *DashboardCache) Report(c context.Context, query dto.ReportQuery) (res dto.Report, err *helper.ErrorStruct) {
+	return cached(a, c, "Report", cacheReports, a.Config.ReportsTTL, query, func() (dto.Report, *helper.ErrorStruct) {
+		return a.DashboardService.Report(c, query)
+	})
+}
+
+func (a *DashboardCache) DriverReport(c context.Context, query dto.DriverReportQuery) (res dto.DriverReport, err *helper.ErrorStruct) {
+	return cached(a, c, "DriverReport", cacheReports, a.Config.ReportsTTL, query, func() (dto.DriverReport, *helper.ErrorStruct) {
+		return a.DashboardService.DriverReport(c, query)
+	})
+}
+
+func (a *DashboardCache) Demand(c context.Context, query dto.DemandQuery) (res dto.Demand, err *helper.ErrorStruct) {
+	return cached(a, c, "Demand", cacheReports, a.Config.ReportsTTL, query, func() (dto.Demand, *helper.ErrorStruct) {
+		return a.DashboardService.Demand(c, query)
+	})
+}
+
+type cachedDrivers struct {
+	Drivers []models.Drivers
+	Page    dto.PageInfo
+}
+
+func (a *DashboardCache) GetAllDrivers(c context.Context, q dto.ListQuery) (res []models.Drivers, page dto.PageInfo, err *helper.ErrorStruct) {
+	drivers, err := cached(a, c, "GetAllDrivers", cacheDrivers, a.Config.DriversTTL, q, func() (cachedDrivers, *helper.ErrorStruct) {
+		res, page, err := a.DashboardService.GetAllDrivers(c, q)
+		return cachedDrivers{Drivers: res, Page: page}, err
+	})
+
+	return drivers.Drivers, drivers.Page, err
+}
+
+func (a *DashboardCache) AddRoute(c context.Context, data dto.AddRoute) (res models.Route, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.AddRoute(c, data)
+	a.invalidate(c, err, cacheRoutes, cacheReports)
+	return res, err
+}
+
+func (a *DashboardCache) EditAmountRoute(c context.Context, data dto.EditAmount, id string) (res models.Route, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.EditAmountRoute(c, data, id)
+	a.invalidate(c, err, cacheRoutes, cacheReports)
+	return res, err
+}
+
+func (a *DashboardCache) DeleteRoute(c context.Context, id string, version uint) (res string, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.DeleteRoute(c, id, version)
+	a.invalidate(c, err, cacheRoutes, cacheReports)
+	return res, err
+}
+
+func (a *DashboardCache) SetDriverStatusVerified(c context.Context, id string, version uint) (res models.Drivers, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.SetDriverStatusVerified(c, id, version)
+	a.invalidate(c, err, cacheDrivers)
+	return res, err
+}
+
+// A fare taking effect changes the routes and what the report expects trips
+// to take in.
+func (a *DashboardCache) ApplyRouteFares(c context.Context, now time.Time) (res []models.Route, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.ApplyRouteFares(c, now)
+	if len(res) > 0 {
+		a.invalidate(c, err, cacheRoutes, cacheReports)
+	}
+	return res, err
+}
+
+// Deleting and restoring accounts changes the driver list and the totals of
+// the report.
+
+func (a *DashboardCache) DeleteDriver(c context.Context, id string, version uint) (res dto.DeletionReport, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.DeleteDriver(c, id, version)
+	a.invalidate(c, err, cacheDrivers, cacheReports)
+	return res, err
+}
+
+func (a *DashboardCache) RestoreDriver(c context.Context, id string) (res string, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.RestoreDriver(c, id)
+	a.invalidate(c, err, cacheDrivers, cacheReports)
+	return res, err
+}
+
+func (a *DashboardCache) DeleteUser(c context.Context, id string) (res dto.DeletionReport, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.DeleteUser(c, id)
+	a.invalidate(c, err, cacheReports)
+	return res, err
+}
+
+func (a *DashboardCache) RestoreUser(c context.Context, id string) (res string, err *helper.ErrorStruct) {
+	res, err = a.DashboardService.RestoreUser(c, id)
+	a.invalidate(c, err, cacheReports)
+	return res, err
+}
+
+// Stats returns the hit and miss counters of this instance since it started.
+func (a *DashboardCache) Stats(c context.Context) dto.CacheStats {
+	res := dto.CacheStats{
+		Backend:       a.Config.Store.Name(),
+		Methods:       map[string]dto.CacheCounters{},
+		Invalidations: map[string]int64{},
+	}
+
+	for method, counters := range a.methods {
+		res.Methods[method] = dto.CacheCounters{
+			Hits:   counters.hits.Load(),
+			Misses: counters.misses.Load(),
+			Errors: counters.errors.Load(),
+		}
+	}
+
+	for group, count := range a.invalidations {
+		res.Invalidations[group] = count.Load()
+	}
+
+	return res
+}
+
+// cached returns the result of load from the cache, or calls load and caches
+// a successful result. A failing store is logged and treated as a miss, so the
+// cache never fails a request the database could answer.
+func cached[T any](a *DashboardCache, c context.Context, method string, group string, ttl time.Duration, args any, load func() (T, *helper.ErrorStruct)) (T, *helper.ErrorStruct) {
+	counters := a.methods[method]
+
+	key, errKey := a.key(c, method, group, args)
+	if errKey == nil {
+		value, ok, errGet := a.Config.Store.Get(c, key)
+
+		var res T
+		switch {
+		case errGet != nil:
+			errKey = errGet
+		case ok:
+			if errGet = json.Unmarshal(value, &res); errGet == nil {
+				counters.hits.Add(1)
+				return res, nil
+			}
+			errKey = errGet
+		}
+	}
+
+	if errKey != nil {
+		counters.errors.Add(1)
+		log.Printf("cache %s: %v", method, errKey)
+	}
+	counters.misses.Add(1)
+
+	res, err := load()
+	if err != nil || errKey != nil {
+		return res, err
+	}
+
+	value, errJSON := json.Marshal(res)
+	if errJSON == nil {
+		errJSON = a.Config.Store.Set(c, key, value, ttl)
+	}
+	if errJSON != nil {
+		counters.errors.Add(1)
+		log.Printf("cache %s: %v", method, errJSON)
+	}
+
+	return res, nil
+}
+
+// key is the cache key of a call. It is taken before the call runs, so a
+// result computed while the group is invalidated lands under the old
+// generation and is never read.
+func (a *DashboardCache) key(c context.Context, method string, group string, args any) (string, error) {
+	generation, ok, err := a.Config.Store.Get(c, "generation:"+group)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		if generation, err = a.newGeneration(c, group); err != nil {
+			return "", err
+		}
+	}
+
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%s:%s:%s", group, generation, method, encoded), nil
+}
+
+func (a *DashboardCache) newGeneration(c context.Context, group string) ([]byte, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	generation := []byte(hex.EncodeToString(buf))
+	if err := a.Config.Store.Set(c, "generation:"+group, generation, 0); err != nil {
+		return nil, err
+	}
+
+	return generation, nil
+}
+
+// invalidate drops the cached results of groups after a successful mutation.
+func (a *DashboardCache) invalidate(c context.Context, err *helper.ErrorStruct, groups ...string) {
+	if err != nil {
+		return
+	}
+
+	for _, group := range groups {
+		a.invalidations[group].Add(1)
+
+		if _, err := a.newGeneration(c, group); err != nil {
+			log.Printf("cache invalidate %s: %v", group, err)
+		}
+	}
+}
+
+// NewDashboardCache wraps next with the cache of cfg.
+func NewDashboardCache(next DashboardService, cfg CacheConfig) *DashboardCache {
+	res := &DashboardCache{
+		DashboardService: next,
+		Config:           cfg,
+		methods:          map[string]*cacheCounters{},
+		invalidations:    map[string]*atomic.Int64{},
+	}
+
+	for _, method := range []string{"GetRoutes", "Report", "DriverReport", "Demand", "GetAllDrivers"} {
+		res.methods[method] = &cacheCounters{}
+	}
+
+	for _, group := range []string{cacheRoutes, cacheReports, cacheDrivers} {
+		res.invalidations[group] = &atomic.Int64{}
+	}
+
+	return res
+}
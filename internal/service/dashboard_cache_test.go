@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/cache"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository/repotest"
+)
+
+// cachedReads are the reads of DashboardCache the invalidation tests watch,
+// by the name Stats counts them under.
+var cachedReads = map[string]func(c context.Context, a *DashboardCache) *helper.ErrorStruct{
+	"GetRoutes": func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+		_, err := a.GetRoutes(c)
+		return err
+	},
+	"Report": func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+		_, err := a.Report(c, dto.ReportQuery{})
+		return err
+	},
+	"GetAllDrivers": func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+		_, _, err := a.GetAllDrivers(c, dto.ListQuery{})
+		return err
+	},
+}
+
+func TestDashboardCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c context.Context, a *DashboardCache) *helper.ErrorStruct
+		misses []string
+	}{
+		{"adding a route", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.AddRoute(c, dto.AddRoute{RouteName: "Baru", Price: 3000})
+			return err
+		}, []string{"GetRoutes", "Report"}},
+		{"editing a route", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.EditAmountRoute(c, dto.EditAmount{Amount: 9000}, "1")
+			return err
+		}, []string{"GetRoutes", "Report"}},
+		{"deleting a route", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.DeleteRoute(c, "3", 0)
+			return err
+		}, []string{"GetRoutes", "Report"}},
+		{"a failed mutation", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			if _, err := a.DeleteRoute(c, "99", 0); err == nil {
+				t.Error("deleting route 99 succeeded")
+			}
+			return nil
+		}, nil},
+		{"verifying a driver", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.SetDriverStatusVerified(c, "d2", 0)
+			return err
+		}, []string{"GetAllDrivers"}},
+		{"a fare taking effect", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.ApplyRouteFares(c, time.Now().AddDate(0, 0, 31))
+			return err
+		}, []string{"GetRoutes", "Report"}},
+		{"no fare taking effect", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.ApplyRouteFares(c, time.Now())
+			return err
+		}, nil},
+		{"deleting a driver", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.DeleteDriver(c, "d2", 0)
+			return err
+		}, []string{"GetAllDrivers", "Report"}},
+		{"restoring a driver", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.RestoreDriver(c, "d3")
+			return err
+		}, []string{"GetAllDrivers", "Report"}},
+		{"deleting a passenger", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.DeleteUser(c, "p2")
+			return err
+		}, []string{"Report"}},
+		{"restoring a passenger", func(c context.Context, a *DashboardCache) *helper.ErrorStruct {
+			_, err := a.RestoreUser(c, "p3")
+			return err
+		}, []string{"Report"}},
+	}
+
+	for _, tt := range tests {
+		c := context.Background()
+		dashboard := NewDashboardService(repository.NewMemoryDashboardRepo(repotest.Fixture()), ReportConfig{})
+		a := NewDashboardCache(dashboard, CacheConfig{
+			Store:      cache.NewLRU(100),
+			RoutesTTL:  time.Minute,
+			ReportsTTL: time.Minute,
+			DriversTTL: time.Minute,
+		})
+
+		// The first read fills the cache and the second is a hit.
+		for method, read := range cachedReads {
+			for i := 0; i < 2; i++ {
+				if err := read(c, a); err != nil {
+					t.Fatalf("%s: %s: %v", tt.name, method, err.Err)
+				}
+			}
+		}
+		before := a.Stats(c).Methods
+
+		if err := tt.mutate(c, a); err != nil {
+			t.Fatalf("%s: %v", tt.name, err.Err)
+		}
+
+		for method, read := range cachedReads {
+			if err := read(c, a); err != nil {
+				t.Fatalf("%s: %s: %v", tt.name, method, err.Err)
+			}
+
+			want := int64(0)
+			for _, m := range tt.misses {
+				if m == method {
+					want = 1
+				}
+			}
+			if got := a.Stats(c).Methods[method].Misses - before[method].Misses; got != want {
+				t.Errorf("%s: %s missed %d times, want %d", tt.name, method, got, want)
+			}
+		}
+	}
+}
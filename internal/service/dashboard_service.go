@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"os"
+	"time"
 
 	"net/http"
 
@@ -14,33 +15,44 @@ import (
 )
 
 type DashboardService interface {
-	GetAllDrivers(c context.Context, q dto.GetDriverQuery) (res []models.Drivers, err *helper.ErrorStruct)
-	GetAllPassengers(c context.Context) (res []models.Passengers, err *helper.ErrorStruct)
+	GetAllDrivers(c context.Context, q dto.ListQuery) (res []models.Drivers, page dto.PageInfo, err *helper.ErrorStruct)
+	GetAllPassengers(c context.Context, q dto.ListQuery) (res []models.Passengers, page dto.PageInfo, err *helper.ErrorStruct)
 	GetDriverById(c context.Context, id string) (res models.Drivers, err *helper.ErrorStruct)
 	GetPassengerById(c context.Context, id string) (res models.Passengers, err *helper.ErrorStruct)
-	GetAllReviews(c context.Context) (res []models.Reviews, err *helper.ErrorStruct)
-	GetAllBlockAccount(c context.Context) (res []models.BlockDriver, err *helper.ErrorStruct)
+	GetAllReviews(c context.Context, q dto.ListQuery) (res []models.Reviews, page dto.PageInfo, err *helper.ErrorStruct)
+	GetAllBlockAccount(c context.Context, q dto.ListQuery) (res []models.BlockDriver, page dto.PageInfo, err *helper.ErrorStruct)
 	GetReviewById(c context.Context, id string) (res models.Reviews, err *helper.ErrorStruct)
-	GetAllHistories(c context.Context) (res []models.Histories, err *helper.ErrorStruct)
+	GetAllHistories(c context.Context, q dto.ListQuery) (res []models.Histories, page dto.PageInfo, err *helper.ErrorStruct)
 	EditAmountRoute(c context.Context, data dto.EditAmount, id string) (res models.Route, err *helper.ErrorStruct)
 	BlockAccount(c context.Context, accountId string) (res models.BlockedAccount, err *helper.ErrorStruct)
 	UnblockAccount(c context.Context, accountId string) (res string, err *helper.ErrorStruct)
-	SetDriverStatusVerified(c context.Context, id string) (res string, err *helper.ErrorStruct)
-	DeleteDriver(c context.Context, id string) (res string, err *helper.ErrorStruct)
-	DeleteUser(c context.Context, id string) (res string, err *helper.ErrorStruct)
+	SetDriverStatusVerified(c context.Context, id string, version uint) (res models.Drivers, err *helper.ErrorStruct)
+	DeleteDriver(c context.Context, id string, version uint) (res dto.DeletionReport, err *helper.ErrorStruct)
+	DeleteUser(c context.Context, id string) (res dto.DeletionReport, err *helper.ErrorStruct)
+	RestoreDriver(c context.Context, id string) (res string, err *helper.ErrorStruct)
+	RestoreUser(c context.Context, id string) (res string, err *helper.ErrorStruct)
 	AddRoute(c context.Context, data dto.AddRoute) (res models.Route, err *helper.ErrorStruct)
-	MonthlyReport(c context.Context, query dto.MonthReport) (res dto.Report, err *helper.ErrorStruct)
+	Report(c context.Context, query dto.ReportQuery) (res dto.Report, err *helper.ErrorStruct)
+	DriverReport(c context.Context, query dto.DriverReportQuery) (res dto.DriverReport, err *helper.ErrorStruct)
+	DriverEarnings(c context.Context, id string, query dto.DriverReportQuery) (res dto.DriverEarnings, err *helper.ErrorStruct)
+	Demand(c context.Context, query dto.DemandQuery) (res dto.Demand, err *helper.ErrorStruct)
 	GetImage(c context.Context, id string) (res string, err *helper.ErrorStruct)
 	GetRoutes(c context.Context) (res []models.Route, err *helper.ErrorStruct)
-	DeleteRoute(c context.Context, id string) (res string, err *helper.ErrorStruct)
+	GetRouteById(c context.Context, id string) (res models.Route, err *helper.ErrorStruct)
+	DeleteRoute(c context.Context, id string, version uint) (res string, err *helper.ErrorStruct)
+	GetRouteFares(c context.Context, id string) (res []models.RouteFare, err *helper.ErrorStruct)
+	ScheduleRouteFare(c context.Context, id string, data dto.ScheduleFare) (res models.RouteFare, err *helper.ErrorStruct)
+	CancelRouteFare(c context.Context, id string, fareID string) (res models.RouteFare, err *helper.ErrorStruct)
+	ApplyRouteFares(c context.Context, now time.Time) (res []models.Route, err *helper.ErrorStruct)
 }
 
 type DashboardServiceImpl struct {
 	DashboardRepo repository.DashboardRepo
+	Reports       ReportConfig
 }
 
-func (a *DashboardServiceImpl) DeleteRoute(c context.Context, id string) (res string, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.DeleteRoute(c, id)
+func (a *DashboardServiceImpl) DeleteRoute(c context.Context, id string, version uint) (res string, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.DeleteRoute(c, id, version)
 
 	if errRepo != nil {
 		var code int
@@ -48,6 +60,10 @@ func (a *DashboardServiceImpl) DeleteRoute(c context.Context, id string) (res st
 		switch {
 		case errors.Is(errRepo, helper.ErrNotFound):
 			code = http.StatusNotFound
+		case errors.Is(errRepo, helper.ErrVersionMismatch):
+			code = http.StatusPreconditionFailed
+		case errors.Is(errRepo, helper.ErrInUse):
+			code = http.StatusConflict
 		default:
 			code = http.StatusInternalServerError
 		}
@@ -83,6 +99,139 @@ func (a *DashboardServiceImpl) GetRoutes(c context.Context) (res []models.Route,
 	return resRepo, nil
 }
 
+func (a *DashboardServiceImpl) GetRouteById(c context.Context, id string) (res models.Route, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.GetRouteByID(c, id)
+
+	if errRepo != nil {
+		var code int
+
+		switch {
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Err:  errRepo,
+			Code: code,
+		}
+	}
+
+	return resRepo, nil
+}
+
+// checkScheduleFare refuses a fare change that cannot be scheduled whatever
+// the route, also before it is proposed.
+func checkScheduleFare(data dto.ScheduleFare) *helper.ErrorStruct {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		return &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	// A fare that applies from now on is set with EditAmountRoute.
+	if !data.EffectiveFrom.After(time.Now()) {
+		return &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  helper.ErrFareInEffect,
+		}
+	}
+
+	return nil
+}
+
+func (a *DashboardServiceImpl) GetRouteFares(c context.Context, id string) (res []models.RouteFare, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.GetRouteFares(c, id)
+
+	if errRepo != nil {
+		var code int
+
+		switch {
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Err:  errRepo,
+			Code: code,
+		}
+	}
+
+	return resRepo, nil
+}
+
+func (a *DashboardServiceImpl) ScheduleRouteFare(c context.Context, id string, data dto.ScheduleFare) (res models.RouteFare, err *helper.ErrorStruct) {
+	if err := checkScheduleFare(data); err != nil {
+		return res, err
+	}
+
+	resRepo, errRepo := a.DashboardRepo.ScheduleRouteFare(c, id, models.RouteFare{
+		Amount:        data.Amount,
+		EffectiveFrom: data.EffectiveFrom,
+	})
+
+	if errRepo != nil {
+		var code int
+
+		switch {
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		case errors.Is(errRepo, helper.ErrFareExists):
+			code = http.StatusConflict
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Err:  errRepo,
+			Code: code,
+		}
+	}
+
+	return resRepo, nil
+}
+
+func (a *DashboardServiceImpl) CancelRouteFare(c context.Context, id string, fareID string) (res models.RouteFare, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.CancelRouteFare(c, id, fareID)
+
+	if errRepo != nil {
+		var code int
+
+		switch {
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		case errors.Is(errRepo, helper.ErrFareInEffect):
+			code = http.StatusConflict
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Err:  errRepo,
+			Code: code,
+		}
+	}
+
+	return resRepo, nil
+}
+
+func (a *DashboardServiceImpl) ApplyRouteFares(c context.Context, now time.Time) (res []models.Route, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.ApplyRouteFares(c, now)
+
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Err:  errRepo,
+			Code: http.StatusInternalServerError,
+		}
+	}
+
+	return resRepo, nil
+}
+
 func (a *DashboardServiceImpl) GetImage(c context.Context, id string) (res string, err *helper.ErrorStruct) {
 	resRepo, errRepo := a.DashboardRepo.GetDriverByID(c, id)
 
@@ -112,30 +261,40 @@ func (a *DashboardServiceImpl) GetImage(c context.Context, id string) (res strin
 	return resRepo.KTP, nil
 }
 
-func (a *DashboardServiceImpl) GetAllHistories(c context.Context) (res []models.Histories, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.GetAllTripHistories(c)
+func (a *DashboardServiceImpl) GetAllHistories(c context.Context, q dto.ListQuery) (res []models.Histories, page dto.PageInfo, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, page, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	resRepo, page, errRepo := a.DashboardRepo.GetAllTripHistories(c, q)
 
 	if errRepo != nil {
 		var code int
 		switch {
+		case errors.Is(errRepo, helper.ErrInvalidInput):
+			code = http.StatusBadRequest
 		case errors.Is(errRepo, helper.ErrNotFound):
 			code = http.StatusNotFound
 		default:
 			code = http.StatusInternalServerError
 		}
 
-		return res, &helper.ErrorStruct{
+		return res, page, &helper.ErrorStruct{
 			Code: code,
 			Err:  errRepo,
 		}
 	}
 
-	return resRepo, nil
+	return resRepo, page, nil
 }
 
 func (a *DashboardServiceImpl) EditAmountRoute(c context.Context, data dto.EditAmount, id string) (res models.Route, err *helper.ErrorStruct) {
 	route := models.Route{
-		Amount: data.Amount,
+		Amount:  data.Amount,
+		Version: data.Version,
 	}
 
 	resRepo, errRepo := a.DashboardRepo.EditAmountRoute(c, route, id)
@@ -145,6 +304,10 @@ func (a *DashboardServiceImpl) EditAmountRoute(c context.Context, data dto.EditA
 		switch {
 		case errors.Is(errRepo, helper.ErrNotFound):
 			code = http.StatusNotFound
+		case errors.Is(errRepo, helper.ErrVersionMismatch):
+			code = http.StatusPreconditionFailed
+		case errors.Is(errRepo, helper.ErrFareExists):
+			code = http.StatusConflict
 		default:
 			code = http.StatusInternalServerError
 		}
@@ -158,8 +321,84 @@ func (a *DashboardServiceImpl) EditAmountRoute(c context.Context, data dto.EditA
 	return resRepo, nil
 }
 
-func (a *DashboardServiceImpl) MonthlyReport(c context.Context, query dto.MonthReport) (res dto.Report, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.MonthlyReport(c, query.Month)
+func (a *DashboardServiceImpl) Report(c context.Context, query dto.ReportQuery) (res dto.Report, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(query); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	window, ok := a.Reports.window(query, time.Now())
+	if !ok || tooManyBuckets(window) {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  helper.ErrReportWindow,
+		}
+	}
+
+	resRepo, errRepo := a.DashboardRepo.Report(c, window)
+	if errRepo == nil && query.Compare != "" {
+		var prev dto.Report
+		if prev, errRepo = a.DashboardRepo.Report(c, comparedWindow(window, query.Compare)); errRepo == nil {
+			resRepo.Comparison = compareReports(resRepo, prev, query.Compare)
+		}
+	}
+
+	if errRepo != nil {
+		var code int
+		switch {
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Code: code,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, nil
+}
+
+func (a *DashboardServiceImpl) DriverReport(c context.Context, query dto.DriverReportQuery) (res dto.DriverReport, err *helper.ErrorStruct) {
+	window, err := a.driverWindow(query)
+	if err != nil {
+		return res, err
+	}
+
+	resRepo, errRepo := a.DashboardRepo.DriverEarnings(c, window, "")
+
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	rankDrivers(resRepo, query.Sort, query.Order)
+	if query.Limit > 0 && len(resRepo) > query.Limit {
+		resRepo = resRepo[:query.Limit]
+	}
+
+	return dto.DriverReport{
+		From:    window.From,
+		To:      window.To,
+		Drivers: resRepo,
+	}, nil
+}
+
+// DriverEarnings is the line of one driver in the driver report, without a
+// rank. Sort, Order and Limit of query are ignored.
+func (a *DashboardServiceImpl) DriverEarnings(c context.Context, id string, query dto.DriverReportQuery) (res dto.DriverEarnings, err *helper.ErrorStruct) {
+	window, err := a.driverWindow(query)
+	if err != nil {
+		return res, err
+	}
+
+	resRepo, errRepo := a.DashboardRepo.DriverEarnings(c, window, id)
 
 	if errRepo != nil {
 		var code int
@@ -176,9 +415,57 @@ func (a *DashboardServiceImpl) MonthlyReport(c context.Context, query dto.MonthR
 		}
 	}
 
+	return resRepo[0], nil
+}
+
+func (a *DashboardServiceImpl) Demand(c context.Context, query dto.DemandQuery) (res dto.Demand, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(query); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	// The window is held to as many days as a daily report can cover.
+	window, ok := a.Reports.window(dto.ReportQuery{Month: query.Month, From: query.From, To: query.To, Granularity: "day"}, time.Now())
+	if !ok || tooManyBuckets(window) {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  helper.ErrReportWindow,
+		}
+	}
+
+	resRepo, errRepo := a.DashboardRepo.Demand(c, window)
+
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
 	return resRepo, nil
 }
 
+func (a *DashboardServiceImpl) driverWindow(query dto.DriverReportQuery) (window dto.ReportWindow, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(query); errValidate != nil {
+		return window, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	window, ok := a.Reports.window(dto.ReportQuery{Month: query.Month, From: query.From, To: query.To, Granularity: "day"}, time.Now())
+	if !ok || tooManyBuckets(window) {
+		return window, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  helper.ErrReportWindow,
+		}
+	}
+
+	return window, nil
+}
+
 func (a *DashboardServiceImpl) AddRoute(c context.Context, data dto.AddRoute) (res models.Route, err *helper.ErrorStruct) {
 	resRepo, errRepo := a.DashboardRepo.AddRoute(c, models.Route{
 		RouteName: data.RouteName,
@@ -203,14 +490,16 @@ func (a *DashboardServiceImpl) AddRoute(c context.Context, data dto.AddRoute) (r
 	return resRepo, nil
 }
 
-func (a *DashboardServiceImpl) DeleteDriver(c context.Context, id string) (res string, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.DeleteDriver(c, id)
+func (a *DashboardServiceImpl) DeleteDriver(c context.Context, id string, version uint) (res dto.DeletionReport, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.DeleteDriver(c, id, version)
 
 	if errRepo != nil {
 		var code int
 		switch {
 		case errors.Is(errRepo, helper.ErrNotFound):
 			code = http.StatusNotFound
+		case errors.Is(errRepo, helper.ErrVersionMismatch):
+			code = http.StatusPreconditionFailed
 		default:
 			code = http.StatusInternalServerError
 		}
@@ -224,7 +513,7 @@ func (a *DashboardServiceImpl) DeleteDriver(c context.Context, id string) (res s
 	return resRepo, nil
 }
 
-func (a *DashboardServiceImpl) DeleteUser(c context.Context, id string) (res string, err *helper.ErrorStruct) {
+func (a *DashboardServiceImpl) DeleteUser(c context.Context, id string) (res dto.DeletionReport, err *helper.ErrorStruct) {
 	resRepo, errRepo := a.DashboardRepo.DeleteUser(c, id)
 
 	if errRepo != nil {
@@ -245,8 +534,8 @@ func (a *DashboardServiceImpl) DeleteUser(c context.Context, id string) (res str
 	return resRepo, nil
 }
 
-func (a *DashboardServiceImpl) SetDriverStatusVerified(c context.Context, id string) (res string, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.SetDriverStatusVerified(c, id)
+func (a *DashboardServiceImpl) RestoreDriver(c context.Context, id string) (res string, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.RestoreDriver(c, id)
 
 	if errRepo != nil {
 		var code int
@@ -266,8 +555,8 @@ func (a *DashboardServiceImpl) SetDriverStatusVerified(c context.Context, id str
 	return resRepo, nil
 }
 
-func (a *DashboardServiceImpl) GetAllBlockAccount(c context.Context) (res []models.BlockDriver, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.GetAllBlcokAccount(c)
+func (a *DashboardServiceImpl) RestoreUser(c context.Context, id string) (res string, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.RestoreUser(c, id)
 
 	if errRepo != nil {
 		var code int
@@ -287,14 +576,16 @@ func (a *DashboardServiceImpl) GetAllBlockAccount(c context.Context) (res []mode
 	return resRepo, nil
 }
 
-func (a *DashboardServiceImpl) GetAllReviews(c context.Context) (res []models.Reviews, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.GetAllReview(c)
+func (a *DashboardServiceImpl) SetDriverStatusVerified(c context.Context, id string, version uint) (res models.Drivers, err *helper.ErrorStruct) {
+	resRepo, errRepo := a.DashboardRepo.SetDriverStatusVerified(c, id, version)
 
 	if errRepo != nil {
 		var code int
 		switch {
 		case errors.Is(errRepo, helper.ErrNotFound):
 			code = http.StatusNotFound
+		case errors.Is(errRepo, helper.ErrVersionMismatch):
+			code = http.StatusPreconditionFailed
 		default:
 			code = http.StatusInternalServerError
 		}
@@ -305,9 +596,71 @@ func (a *DashboardServiceImpl) GetAllReviews(c context.Context) (res []models.Re
 		}
 	}
 
+	resRepo.ProfilePicture = os.Getenv("BASE_URL") + "/api/driver/images/" + resRepo.ID
+
 	return resRepo, nil
 }
 
+func (a *DashboardServiceImpl) GetAllBlockAccount(c context.Context, q dto.ListQuery) (res []models.BlockDriver, page dto.PageInfo, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, page, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	resRepo, page, errRepo := a.DashboardRepo.GetAllBlcokAccount(c, q)
+
+	if errRepo != nil {
+		var code int
+		switch {
+		case errors.Is(errRepo, helper.ErrInvalidInput):
+			code = http.StatusBadRequest
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, page, &helper.ErrorStruct{
+			Code: code,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, page, nil
+}
+
+func (a *DashboardServiceImpl) GetAllReviews(c context.Context, q dto.ListQuery) (res []models.Reviews, page dto.PageInfo, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, page, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	resRepo, page, errRepo := a.DashboardRepo.GetAllReview(c, q)
+
+	if errRepo != nil {
+		var code int
+		switch {
+		case errors.Is(errRepo, helper.ErrInvalidInput):
+			code = http.StatusBadRequest
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, page, &helper.ErrorStruct{
+			Code: code,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, page, nil
+}
+
 func (a *DashboardServiceImpl) GetReviewById(c context.Context, id string) (res models.Reviews, err *helper.ErrorStruct) {
 	resRepo, errRepo := a.DashboardRepo.GetReviewById(c, id)
 
@@ -329,19 +682,28 @@ func (a *DashboardServiceImpl) GetReviewById(c context.Context, id string) (res
 	return resRepo, nil
 }
 
-func (a *DashboardServiceImpl) GetAllDrivers(c context.Context, q dto.GetDriverQuery) (res []models.Drivers, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.GetAllDrivers(c, q.Verified)
+func (a *DashboardServiceImpl) GetAllDrivers(c context.Context, q dto.ListQuery) (res []models.Drivers, page dto.PageInfo, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, page, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	resRepo, page, errRepo := a.DashboardRepo.GetAllDrivers(c, q)
 
 	if errRepo != nil {
 		var code int
 		switch {
+		case errors.Is(errRepo, helper.ErrInvalidInput):
+			code = http.StatusBadRequest
 		case errors.Is(errRepo, helper.ErrNotFound):
 			code = http.StatusNotFound
 		default:
 			code = http.StatusInternalServerError
 		}
 
-		return res, &helper.ErrorStruct{
+		return res, page, &helper.ErrorStruct{
 			Code: code,
 			Err:  errRepo,
 		}
@@ -357,28 +719,37 @@ func (a *DashboardServiceImpl) GetAllDrivers(c context.Context, q dto.GetDriverQ
 		}
 	}
 
-	return resRepo, nil
+	return resRepo, page, nil
 }
 
-func (a *DashboardServiceImpl) GetAllPassengers(c context.Context) (res []models.Passengers, err *helper.ErrorStruct) {
-	resRepo, errRepo := a.DashboardRepo.GetAllPassengers(c)
+func (a *DashboardServiceImpl) GetAllPassengers(c context.Context, q dto.ListQuery) (res []models.Passengers, page dto.PageInfo, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, page, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	resRepo, page, errRepo := a.DashboardRepo.GetAllPassengers(c, q)
 
 	if errRepo != nil {
 		var code int
 		switch {
+		case errors.Is(errRepo, helper.ErrInvalidInput):
+			code = http.StatusBadRequest
 		case errors.Is(errRepo, helper.ErrNotFound):
 			code = http.StatusNotFound
 		default:
 			code = http.StatusInternalServerError
 		}
 
-		return res, &helper.ErrorStruct{
+		return res, page, &helper.ErrorStruct{
 			Code: code,
 			Err:  errRepo,
 		}
 	}
 
-	return resRepo, nil
+	return resRepo, page, nil
 }
 
 func (a *DashboardServiceImpl) GetDriverById(c context.Context, id string) (res models.Drivers, err *helper.ErrorStruct) {
@@ -473,8 +844,9 @@ func (a *DashboardServiceImpl) BlockAccount(c context.Context, accountId string)
 	return resRepo, nil
 }
 
-func NewDashboardService(DashboardRepo repository.DashboardRepo) DashboardService {
+func NewDashboardService(DashboardRepo repository.DashboardRepo, reports ReportConfig) DashboardService {
 	return &DashboardServiceImpl{
 		DashboardRepo: DashboardRepo,
+		Reports:       reports,
 	}
 }
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/export"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+)
+
+// exportPageSize is how many rows an export reads at a time.
+const exportPageSize = 200
+
+// ExportService turns the lists and the report of the dashboard into export
+// tables. A table reads its first page before it is returned, so a bad query
+// fails with an error instead of a broken file.
+type ExportService interface {
+	Histories(c context.Context, q dto.ListQuery) (res export.Table, err *helper.ErrorStruct)
+	Drivers(c context.Context, q dto.ListQuery) (res export.Table, err *helper.ErrorStruct)
+	Passengers(c context.Context, q dto.ListQuery) (res export.Table, err *helper.ErrorStruct)
+	Reviews(c context.Context, q dto.ListQuery) (res export.Table, err *helper.ErrorStruct)
+	Report(c context.Context, q dto.ReportExport) (res []export.Table, err *helper.ErrorStruct)
+	MonthlyReport(c context.Context, q dto.MonthlyReportQuery) (res dto.MonthlyReport, err *helper.ErrorStruct)
+}
+
+type ExportServiceImpl struct {
+	DashboardService DashboardService
+	Reports          ReportConfig
+}
+
+func (a *ExportServiceImpl) Histories(c context.Context, q dto.ListQuery) (res export.Table, err *helper.ErrorStruct) {
+	columns := []export.Column{
+		{Header: "ID", Kind: export.Number},
+		{Header: "Penumpang", Kind: export.Text},
+		{Header: "Pengemudi", Kind: export.Text},
+		{Header: "Rute", Kind: export.Text},
+		{Header: "Tarif", Kind: export.Rupiah},
+		{Header: "Dibayar", Kind: export.Rupiah},
+		{Header: "Waktu", Kind: export.Time},
+	}
+
+	return exportPages(c, q, "Riwayat Perjalanan", columns, a.DashboardService.GetAllHistories, func(h models.Histories) []any {
+		return []any{h.ID, h.PassengerName, h.DriverName, h.Route, h.Fare, h.Amount, a.Reports.local(h.CreatedAt)}
+	})
+}
+
+func (a *ExportServiceImpl) Drivers(c context.Context, q dto.ListQuery) (res export.Table, err *helper.ErrorStruct) {
+	columns := []export.Column{
+		{Header: "ID", Kind: export.Text},
+		{Header: "Email", Kind: export.Text},
+		{Header: "Nama", Kind: export.Text},
+		{Header: "No. Telepon", Kind: export.Text},
+		{Header: "No. SIM", Kind: export.Text},
+		{Header: "Terverifikasi", Kind: export.Text},
+		{Header: "Status", Kind: export.Text},
+		{Header: "Dihapus", Kind: export.Time},
+	}
+
+	return exportPages(c, q, "Pengemudi", columns, a.DashboardService.GetAllDrivers, func(d models.Drivers) []any {
+		verified := "Tidak"
+		if d.Verified {
+			verified = "Ya"
+		}
+
+		return []any{d.ID, d.Email, d.Name, d.PhoneNumber, d.LicenseNumber, verified, d.Status, a.Reports.localPtr(d.DeletedAt)}
+	})
+}
+
+func (a *ExportServiceImpl) Passengers(c context.Context, q dto.ListQuery) (res export.Table, err *helper.ErrorStruct) {
+	columns := []export.Column{
+		{Header: "ID", Kind: export.Text},
+		{Header: "Email", Kind: export.Text},
+		{Header: "Nama", Kind: export.Text},
+		{Header: "Tanggal Lahir", Kind: export.Date},
+		{Header: "Umur", Kind: export.Number},
+		{Header: "Dihapus", Kind: export.Time},
+	}
+
+	return exportPages(c, q, "Penumpang", columns, a.DashboardService.GetAllPassengers, func(p models.Passengers) []any {
+		return []any{p.ID, p.Email, p.Name, p.DateOfBirth, p.Age, a.Reports.localPtr(p.DeletedAt)}
+	})
+}
+
+func (a *ExportServiceImpl) Reviews(c context.Context, q dto.ListQuery) (res export.Table, err *helper.ErrorStruct) {
+	columns := []export.Column{
+		{Header: "ID", Kind: export.Number},
+		{Header: "Penumpang", Kind: export.Text},
+		{Header: "Pengemudi", Kind: export.Text},
+		{Header: "Komentar", Kind: export.Text},
+		{Header: "Bintang", Kind: export.Number},
+	}
+
+	return exportPages(c, q, "Ulasan", columns, a.DashboardService.GetAllReviews, func(r models.Reviews) []any {
+		return []any{r.ID, r.PassengerName, r.DriverName, r.Comment, r.Star}
+	})
+}
+
+// Report exports the routes and the time series of a report, or only the one
+// q.Table names.
+func (a *ExportServiceImpl) Report(c context.Context, q dto.ReportExport) (res []export.Table, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	report, err := a.DashboardService.Report(c, q.ReportQuery)
+	if err != nil {
+		return res, err
+	}
+
+	if q.Table == "" || q.Table == "routes" {
+		routes := make([][]any, 0, len(report.Trips))
+		for _, r := range report.Trips {
+			routes = append(routes, []any{r.Route, r.Total, r.Revenue, r.FareRevenue, r.ActiveDrivers, r.ActivePassengers})
+		}
+
+		res = append(res, export.Table{
+			Name: "Rute",
+			Columns: []export.Column{
+				{Header: "Rute", Kind: export.Text},
+				{Header: "Perjalanan", Kind: export.Number},
+				{Header: "Pendapatan", Kind: export.Rupiah},
+				{Header: "Pendapatan Sesuai Tarif", Kind: export.Rupiah},
+				{Header: "Pengemudi Aktif", Kind: export.Number},
+				{Header: "Penumpang Aktif", Kind: export.Number},
+			},
+			Next: exportRows(routes),
+		})
+	}
+
+	if q.Table == "" || q.Table == "series" {
+		// Hours need the time of day, longer buckets only their first day.
+		start := export.Date
+		if report.Granularity == "hour" {
+			start = export.Time
+		}
+
+		series := make([][]any, 0, len(report.Series))
+		for _, b := range report.Series {
+			series = append(series, []any{b.Start, b.Trips, b.Revenue, b.ActiveDrivers, b.ActivePassengers})
+		}
+
+		res = append(res, export.Table{
+			Name: "Periode",
+			Columns: []export.Column{
+				{Header: "Mulai", Kind: start},
+				{Header: "Perjalanan", Kind: export.Number},
+				{Header: "Pendapatan", Kind: export.Rupiah},
+				{Header: "Pengemudi Aktif", Kind: export.Number},
+				{Header: "Penumpang Aktif", Kind: export.Number},
+			},
+			Next: exportRows(series),
+		})
+	}
+
+	return res, nil
+}
+
+// MonthlyReport collects the printable report of a calendar month in the
+// report time zone.
+func (a *ExportServiceImpl) MonthlyReport(c context.Context, q dto.MonthlyReportQuery) (res dto.MonthlyReport, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	loc := a.Reports.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	res.GeneratedAt = time.Now().In(loc)
+	if q.Month == "" {
+		res.Month = helper.BucketStart(res.GeneratedAt, "month").AddDate(0, -1, 0)
+	} else {
+		// Validated above.
+		res.Month, _ = time.ParseInLocation("2006-01", q.Month, loc)
+	}
+	end := res.Month.AddDate(0, 1, 0)
+
+	if res.Report, err = a.DashboardService.Report(c, dto.ReportQuery{From: res.Month, To: end, Granularity: "day", Compare: "previous"}); err != nil {
+		return res, err
+	}
+
+	drivers, err := a.DashboardService.DriverReport(c, dto.DriverReportQuery{From: res.Month, To: end})
+	if err != nil {
+		return res, err
+	}
+	res.Drivers = drivers.Drivers
+
+	return res, nil
+}
+
+// exportPages is a table of the rows list returns for q, a page at a time.
+// The first page is read right away.
+func exportPages[T any](c context.Context, q dto.ListQuery, name string, columns []export.Column, list func(context.Context, dto.ListQuery) ([]T, dto.PageInfo, *helper.ErrorStruct), row func(T) []any) (export.Table, *helper.ErrorStruct) {
+	q.Limit = exportPageSize
+
+	items, page, err := list(c, q)
+	if err != nil {
+		return export.Table{}, err
+	}
+
+	first := true
+	next := func() ([][]any, error) {
+		if !first {
+			if !page.HasMore {
+				return nil, nil
+			}
+
+			q.Cursor = page.NextCursor
+			if items, page, err = list(c, q); err != nil {
+				return nil, err.Err
+			}
+		}
+		first = false
+
+		rows := make([][]any, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, row(item))
+		}
+
+		return rows, nil
+	}
+
+	return export.Table{Name: name, Columns: columns, Next: next}, nil
+}
+
+// exportRows is the Next of a table whose rows are already in memory.
+func exportRows(rows [][]any) func() ([][]any, error) {
+	return func() ([][]any, error) {
+		res := rows
+		rows = nil
+		return res, nil
+	}
+}
+
+// local is t in the report time zone, so exported times read the same as the
+// days of the report.
+func (a ReportConfig) local(t time.Time) time.Time {
+	if a.Location == nil || t.IsZero() {
+		return t
+	}
+
+	return t.In(a.Location)
+}
+
+func (a ReportConfig) localPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	local := a.local(*t)
+	return &local
+}
+
+func NewExportService(dashboardService DashboardService, reports ReportConfig) ExportService {
+	return &ExportServiceImpl{
+		DashboardService: dashboardService,
+		Reports:          reports,
+	}
+}
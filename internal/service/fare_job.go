@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+)
+
+// FareConfig controls the job that applies scheduled fares once they take
+// effect.
+type FareConfig struct {
+	Interval time.Duration
+}
+
+// FareConfigFromEnv reads FARE_APPLY_INTERVAL (default 1m).
+func FareConfigFromEnv() (cfg FareConfig, err error) {
+	cfg.Interval = time.Minute
+	if v := os.Getenv("FARE_APPLY_INTERVAL"); v != "" {
+		if cfg.Interval, err = time.ParseDuration(v); err != nil || cfg.Interval <= 0 {
+			return cfg, fmt.Errorf("FARE_APPLY_INTERVAL: invalid duration %q", v)
+		}
+	}
+
+	return cfg, nil
+}
+
+// FareJob writes fares that have taken effect into the routes. It goes
+// through the DashboardService so a cache in front of it sees the change.
+type FareJob struct {
+	DashboardService DashboardService
+	Config           FareConfig
+}
+
+// Run applies fares once immediately and then every interval until c is done.
+func (a *FareJob) Run(c context.Context) {
+	c = helper.WithActor(c, helper.Actor{Type: "system", ID: "fares"})
+
+	ticker := time.NewTicker(a.Config.Interval)
+	defer ticker.Stop()
+
+	for {
+		res, err := a.DashboardService.ApplyRouteFares(c, time.Now())
+		if err != nil {
+			log.Printf("apply route fares: %v", err.Err)
+		}
+		for _, route := range res {
+			log.Printf("route %d fare is now %d", route.ID, route.Amount)
+		}
+
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func NewFareJob(dashboardService DashboardService, config FareConfig) *FareJob {
+	return &FareJob{
+		DashboardService: dashboardService,
+		Config:           config,
+	}
+}
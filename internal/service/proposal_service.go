@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+)
+
+// Actions that can be put behind an approval. The names match the audit log.
+const (
+	ActionDeleteDriver    = "driver.delete"
+	ActionDeletePassenger = "passenger.delete"
+	ActionUpdateRoute     = "route.update"
+	ActionDeleteRoute     = "route.delete"
+	ActionScheduleFare    = "route.fare_schedule"
+	ActionCancelFare      = "route.fare_cancel"
+)
+
+type proposalExecutor func(c context.Context, dashboard DashboardService, p models.Proposal) *helper.ErrorStruct
+
+// proposalAction is an action that can be put behind an approval. Approving
+// it takes permission on top of proposals:decide. check, when set, refuses a
+// proposal the action would fail on before it is made.
+type proposalAction struct {
+	permission string
+	check      func(p models.Proposal) *helper.ErrorStruct
+	execute    proposalExecutor
+}
+
+var proposalActions = map[string]proposalAction{
+	ActionDeleteDriver: {
+		permission: models.PermDriversDelete,
+		execute: func(c context.Context, dashboard DashboardService, p models.Proposal) *helper.ErrorStruct {
+			var data dto.Precondition
+			if err := unmarshalPayload(p, &data); err != nil {
+				return err
+			}
+
+			_, err := dashboard.DeleteDriver(c, p.TargetID, data.Version)
+			return err
+		},
+	},
+	ActionDeletePassenger: {
+		permission: models.PermUsersDelete,
+		execute: func(c context.Context, dashboard DashboardService, p models.Proposal) *helper.ErrorStruct {
+			_, err := dashboard.DeleteUser(c, p.TargetID)
+			return err
+		},
+	},
+	ActionUpdateRoute: {
+		permission: models.PermRoutesWrite,
+		execute: func(c context.Context, dashboard DashboardService, p models.Proposal) *helper.ErrorStruct {
+			var data dto.EditAmount
+			if err := unmarshalPayload(p, &data); err != nil {
+				return err
+			}
+
+			_, err := dashboard.EditAmountRoute(c, data, p.TargetID)
+			return err
+		},
+	},
+	ActionDeleteRoute: {
+		permission: models.PermRoutesWrite,
+		execute: func(c context.Context, dashboard DashboardService, p models.Proposal) *helper.ErrorStruct {
+			var data dto.Precondition
+			if err := unmarshalPayload(p, &data); err != nil {
+				return err
+			}
+
+			_, err := dashboard.DeleteRoute(c, p.TargetID, data.Version)
+			return err
+		},
+	},
+	ActionScheduleFare: {
+		permission: models.PermRoutesWrite,
+		check: func(p models.Proposal) *helper.ErrorStruct {
+			var data dto.ScheduleFare
+			if err := unmarshalPayload(p, &data); err != nil {
+				return err
+			}
+
+			return checkScheduleFare(data)
+		},
+		execute: func(c context.Context, dashboard DashboardService, p models.Proposal) *helper.ErrorStruct {
+			var data dto.ScheduleFare
+			if err := unmarshalPayload(p, &data); err != nil {
+				return err
+			}
+
+			_, err := dashboard.ScheduleRouteFare(c, p.TargetID, data)
+			return err
+		},
+	},
+	ActionCancelFare: {
+		permission: models.PermRoutesWrite,
+		execute: func(c context.Context, dashboard DashboardService, p models.Proposal) *helper.ErrorStruct {
+			var data dto.CancelFare
+			if err := unmarshalPayload(p, &data); err != nil {
+				return err
+			}
+
+			_, err := dashboard.CancelRouteFare(c, p.TargetID, data.FareID)
+			return err
+		},
+	},
+}
+
+// unmarshalPayload decodes the payload of p into v. Proposals made before
+// deletes carried a precondition have a null payload, which leaves v zero.
+func unmarshalPayload(p models.Proposal, v any) *helper.ErrorStruct {
+	if err := json.Unmarshal(p.Payload, v); err != nil {
+		return &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
+// ApprovalConfig selects which actions need a second admin and how long a
+// proposal stays open.
+type ApprovalConfig struct {
+	Actions []string
+	TTL     time.Duration
+}
+
+// ApprovalConfigFromEnv reads APPROVAL_ACTIONS, a comma separated list of
+// actions (default
+// "driver.delete,route.update,route.fare_schedule,route.fare_cancel", "none"
+// to disable), and APPROVAL_TTL (default 72h).
+func ApprovalConfigFromEnv() (cfg ApprovalConfig, err error) {
+	actions, ok := os.LookupEnv("APPROVAL_ACTIONS")
+	if !ok {
+		actions = ActionDeleteDriver + "," + ActionUpdateRoute + "," + ActionScheduleFare + "," + ActionCancelFare
+	}
+
+	for _, action := range strings.Split(actions, ",") {
+		action = strings.TrimSpace(action)
+		if action == "" || action == "none" {
+			continue
+		}
+		if _, ok := proposalActions[action]; !ok {
+			return cfg, fmt.Errorf("APPROVAL_ACTIONS: unknown action %q", action)
+		}
+		cfg.Actions = append(cfg.Actions, action)
+	}
+
+	cfg.TTL = 72 * time.Hour
+	if v := os.Getenv("APPROVAL_TTL"); v != "" {
+		if cfg.TTL, err = time.ParseDuration(v); err != nil || cfg.TTL <= 0 {
+			return cfg, fmt.Errorf("APPROVAL_TTL: invalid duration %q", v)
+		}
+	}
+
+	return cfg, nil
+}
+
+type ProposalService interface {
+	Requires(action string) bool
+	Propose(c context.Context, action string, targetID string, payload any) (res models.Proposal, err *helper.ErrorStruct)
+	GetProposals(c context.Context, q dto.ProposalQuery) (res []models.Proposal, err *helper.ErrorStruct)
+	Approve(c context.Context, id string) (res models.Proposal, err *helper.ErrorStruct)
+	Reject(c context.Context, id string, data dto.RejectProposal) (res models.Proposal, err *helper.ErrorStruct)
+}
+
+type ProposalServiceImpl struct {
+	ProposalRepo     repository.ProposalRepo
+	DashboardService DashboardService
+	Config           ApprovalConfig
+}
+
+func (a *ProposalServiceImpl) Requires(action string) bool {
+	for _, v := range a.Config.Actions {
+		if v == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *ProposalServiceImpl) Propose(c context.Context, action string, targetID string, payload any) (res models.Proposal, err *helper.ErrorStruct) {
+	raw, errJson := json.Marshal(payload)
+	if errJson != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errJson,
+		}
+	}
+
+	proposal := models.Proposal{
+		Action:      action,
+		TargetID:    targetID,
+		Payload:     models.JSON(raw),
+		Status:      models.ProposalPending,
+		RequestedBy: helper.ActorFrom(c).ID,
+		ExpiresAt:   time.Now().Add(a.Config.TTL),
+	}
+
+	if check := proposalActions[action].check; check != nil {
+		if err := check(proposal); err != nil {
+			return res, err
+		}
+	}
+
+	resRepo, errRepo := a.ProposalRepo.CreateProposal(c, proposal)
+
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, nil
+}
+
+func (a *ProposalServiceImpl) GetProposals(c context.Context, q dto.ProposalQuery) (res []models.Proposal, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	if errRepo := a.ProposalRepo.ExpireProposals(c, time.Now()); errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	resRepo, errRepo := a.ProposalRepo.GetProposals(c, q.Status)
+
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, nil
+}
+
+// Approve runs the proposed action as the approving admin, who needs the
+// permission of the action as well. The proposal is executing while the
+// action runs and approved after it. If the action fails the proposal goes
+// back to pending so it can be retried or rejected.
+func (a *ProposalServiceImpl) Approve(c context.Context, id string) (res models.Proposal, err *helper.ErrorStruct) {
+	resRepo, err := a.decide(c, id, models.ProposalExecuting, "")
+	if err != nil {
+		return res, err
+	}
+
+	err = proposalActions[resRepo.Action].execute(c, a.DashboardService, resRepo)
+
+	if err != nil {
+		if errRepo := a.ProposalRepo.ReleaseProposal(c, id); errRepo != nil {
+			return res, &helper.ErrorStruct{
+				Code: http.StatusInternalServerError,
+				Err:  errRepo,
+			}
+		}
+		return res, err
+	}
+
+	resRepo, errRepo := a.ProposalRepo.FinishProposal(c, id)
+	if errRepo != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusInternalServerError,
+			Err:  errRepo,
+		}
+	}
+
+	return resRepo, nil
+}
+
+// Reject closes the proposal without running its action. The requester can
+// reject their own proposal to withdraw it.
+func (a *ProposalServiceImpl) Reject(c context.Context, id string, data dto.RejectProposal) (res models.Proposal, err *helper.ErrorStruct) {
+	if errValidate := helper.Validate.Struct(data); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	return a.decide(c, id, models.ProposalRejected, data.Note)
+}
+
+func (a *ProposalServiceImpl) decide(c context.Context, id string, status string, note string) (res models.Proposal, err *helper.ErrorStruct) {
+	actor := helper.ActorFrom(c)
+
+	proposal, errRepo := a.ProposalRepo.GetProposalByID(c, id)
+	if errRepo == nil && status == models.ProposalExecuting {
+		if action, ok := proposalActions[proposal.Action]; !ok {
+			errRepo = fmt.Errorf("unknown proposed action %q", proposal.Action)
+		} else if proposal.RequestedBy == actor.ID {
+			errRepo = helper.ErrSelfApproval
+		} else if !actor.Can(action.permission) {
+			errRepo = helper.ErrActionPermission
+		}
+	}
+	if errRepo == nil {
+		proposal, errRepo = a.ProposalRepo.ClaimProposal(c, id, status, actor.ID, note)
+	}
+
+	if errRepo != nil {
+		var code int
+		switch {
+		case errors.Is(errRepo, helper.ErrNotFound):
+			code = http.StatusNotFound
+		case errors.Is(errRepo, helper.ErrSelfApproval), errors.Is(errRepo, helper.ErrActionPermission):
+			code = http.StatusForbidden
+		case errors.Is(errRepo, helper.ErrProposalClosed):
+			code = http.StatusConflict
+		default:
+			code = http.StatusInternalServerError
+		}
+
+		return res, &helper.ErrorStruct{
+			Code: code,
+			Err:  errRepo,
+		}
+	}
+
+	return proposal, nil
+}
+
+func NewProposalService(proposalRepo repository.ProposalRepo, dashboardService DashboardService, config ApprovalConfig) ProposalService {
+	return &ProposalServiceImpl{
+		ProposalRepo:     proposalRepo,
+		DashboardService: dashboardService,
+		Config:           config,
+	}
+}
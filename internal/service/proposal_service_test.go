@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/migrations"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/models"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository/repotest"
+)
+
+// newTestProposals keeps proposals in a migrated SQLite database and runs
+// their actions against an in-memory repository holding repotest.Fixture.
+func newTestProposals(t *testing.T) (ProposalService, repository.ProposalRepo, DashboardService) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_NAME", filepath.Join(t.TempDir(), "proposals.db"))
+
+	db := models.DatabaseInit()
+	// SQLite fails rather than waits when two transactions that have read
+	// both go on to write, so the approvals take turns on one connection.
+	// The conditional update still decides which of them wins.
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	m, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	proposals := repository.NewProposalRepo(db)
+	dashboard := NewDashboardService(repository.NewMemoryDashboardRepo(repotest.Fixture()), ReportConfig{})
+	config := ApprovalConfig{Actions: []string{ActionScheduleFare, ActionDeleteRoute}, TTL: time.Hour}
+
+	return NewProposalService(proposals, dashboard, config), proposals, dashboard
+}
+
+func as(id string, permissions ...string) context.Context {
+	return helper.WithActor(context.Background(), helper.Actor{Type: "admin", ID: id, Permissions: permissions})
+}
+
+func proposeFare(t *testing.T, proposals ProposalService, amount int) models.Proposal {
+	p, err := proposals.Propose(as("maker", models.PermRoutesWrite), ActionScheduleFare, "1", dto.ScheduleFare{
+		Amount:        amount,
+		EffectiveFrom: time.Now().Add(48 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err.Err)
+	}
+
+	return p
+}
+
+func proposalID(p models.Proposal) string {
+	return strconv.FormatUint(uint64(p.ID), 10)
+}
+
+func TestApproveRunsTheAction(t *testing.T) {
+	proposals, _, dashboard := newTestProposals(t)
+	p := proposeFare(t, proposals, 6500)
+
+	res, err := proposals.Approve(as("checker", models.PermRoutesWrite), proposalID(p))
+	if err != nil {
+		t.Fatal(err.Err)
+	}
+	if res.Status != models.ProposalApproved || res.DecidedBy != "checker" {
+		t.Fatalf("got %s decided by %q, want approved by checker", res.Status, res.DecidedBy)
+	}
+
+	fares, err := dashboard.GetRouteFares(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err.Err)
+	}
+	if !slices.ContainsFunc(fares, func(f models.RouteFare) bool { return f.Amount == 6500 }) {
+		t.Fatalf("got fares %+v, want one of 6500", fares)
+	}
+}
+
+func TestRequesterCanOnlyWithdraw(t *testing.T) {
+	proposals, _, _ := newTestProposals(t)
+	p := proposeFare(t, proposals, 6500)
+
+	_, err := proposals.Approve(as("maker", models.PermRoutesWrite), proposalID(p))
+	if err == nil || !errors.Is(err.Err, helper.ErrSelfApproval) {
+		t.Fatalf("approving their own proposal: got %v, want %v", err, helper.ErrSelfApproval)
+	}
+
+	res, err := proposals.Reject(as("maker"), proposalID(p), dto.RejectProposal{Note: "salah harga"})
+	if err != nil {
+		t.Fatal(err.Err)
+	}
+	if res.Status != models.ProposalRejected {
+		t.Fatalf("withdrawing: got %s, want rejected", res.Status)
+	}
+}
+
+func TestApproverNeedsThePermissionOfTheAction(t *testing.T) {
+	proposals, _, _ := newTestProposals(t)
+	p := proposeFare(t, proposals, 6500)
+
+	_, err := proposals.Approve(as("checker", models.PermProposalsDecide), proposalID(p))
+	if err == nil || !errors.Is(err.Err, helper.ErrActionPermission) {
+		t.Fatalf("got %v, want %v", err, helper.ErrActionPermission)
+	}
+
+	// A checker without the permission can still reject.
+	if _, err := proposals.Reject(as("checker", models.PermProposalsDecide), proposalID(p), dto.RejectProposal{}); err != nil {
+		t.Fatal(err.Err)
+	}
+}
+
+func TestExpiredProposalCannotBeClaimed(t *testing.T) {
+	proposals, repo, _ := newTestProposals(t)
+
+	p, errRepo := repo.CreateProposal(context.Background(), models.Proposal{
+		Action:      ActionDeleteRoute,
+		TargetID:    "3",
+		Payload:     models.JSON(`{}`),
+		Status:      models.ProposalPending,
+		RequestedBy: "maker",
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	})
+	if errRepo != nil {
+		t.Fatal(errRepo)
+	}
+
+	_, err := proposals.Approve(as("checker", models.PermRoutesWrite), proposalID(p))
+	if err == nil || !errors.Is(err.Err, helper.ErrProposalClosed) {
+		t.Fatalf("got %v, want %v", err, helper.ErrProposalClosed)
+	}
+}
+
+func TestConcurrentApprovalsRunTheActionOnce(t *testing.T) {
+	proposals, _, dashboard := newTestProposals(t)
+	p := proposeFare(t, proposals, 6500)
+
+	before, _ := dashboard.GetRouteFares(context.Background(), "1")
+
+	var wg sync.WaitGroup
+	errs := make([]*helper.ErrorStruct, 2)
+	for i, checker := range []string{"checker1", "checker2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = proposals.Approve(as(checker, models.PermRoutesWrite), proposalID(p))
+		}()
+	}
+	wg.Wait()
+
+	var approved, closed int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			approved++
+		case errors.Is(err.Err, helper.ErrProposalClosed):
+			closed++
+		default:
+			t.Fatalf("unexpected error %v", err.Err)
+		}
+	}
+	if approved != 1 || closed != 1 {
+		t.Fatalf("got %d approvals and %d closed, want 1 and 1", approved, closed)
+	}
+
+	after, _ := dashboard.GetRouteFares(context.Background(), "1")
+	if len(after) != len(before)+1 {
+		t.Fatalf("got %d fares, want %d", len(after), len(before)+1)
+	}
+}
+
+func TestFailedActionReleasesTheProposal(t *testing.T) {
+	proposals, repo, _ := newTestProposals(t)
+
+	p, err := proposals.Propose(as("maker", models.PermRoutesWrite), ActionDeleteRoute, "99", dto.Precondition{})
+	if err != nil {
+		t.Fatal(err.Err)
+	}
+
+	if _, err := proposals.Approve(as("checker", models.PermRoutesWrite), proposalID(p)); err == nil || !errors.Is(err.Err, helper.ErrNotFound) {
+		t.Fatalf("got %v, want %v", err, helper.ErrNotFound)
+	}
+
+	res, errRepo := repo.GetProposalByID(context.Background(), proposalID(p))
+	if errRepo != nil {
+		t.Fatal(errRepo)
+	}
+	if res.Status != models.ProposalPending || res.DecidedBy != "" {
+		t.Fatalf("got %s decided by %q, want pending and undecided", res.Status, res.DecidedBy)
+	}
+}
+
+func TestSweepRecoversStuckProposals(t *testing.T) {
+	proposals, repo, _ := newTestProposals(t)
+	p := proposeFare(t, proposals, 6500)
+
+	// A process that stopped while running the action leaves the proposal
+	// executing.
+	if _, err := repo.ClaimProposal(context.Background(), proposalID(p), models.ProposalExecuting, "checker", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ExpireProposals(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := repo.GetProposalByID(context.Background(), proposalID(p)); res.Status != models.ProposalExecuting {
+		t.Fatalf("right after the claim: got %s, want executing", res.Status)
+	}
+
+	if err := repo.ExpireProposals(context.Background(), time.Now().Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := repo.GetProposalByID(context.Background(), proposalID(p)); res.Status != models.ProposalPending {
+		t.Fatalf("ten minutes later: got %s, want pending", res.Status)
+	}
+}
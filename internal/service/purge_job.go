@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+)
+
+// PurgeConfig controls the job that permanently removes soft deleted
+// accounts once their retention period is over. Enabled only concerns
+// accounts: the job always deletes revocations of expired tokens.
+type PurgeConfig struct {
+	Enabled   bool
+	Anonymize bool
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// PurgeConfigFromEnv reads PURGE_MODE ("anonymize", the default, "delete" or
+// "off"), PURGE_RETENTION_DAYS (default 30) and PURGE_INTERVAL (default 1h).
+func PurgeConfigFromEnv() (cfg PurgeConfig, err error) {
+	switch mode := os.Getenv("PURGE_MODE"); mode {
+	case "", "anonymize":
+		cfg.Enabled, cfg.Anonymize = true, true
+	case "delete":
+		cfg.Enabled = true
+	case "off":
+	default:
+		return cfg, fmt.Errorf("PURGE_MODE: unknown mode %q", mode)
+	}
+
+	days := 30
+	if v := os.Getenv("PURGE_RETENTION_DAYS"); v != "" {
+		if days, err = strconv.Atoi(v); err != nil || days < 1 {
+			return cfg, fmt.Errorf("PURGE_RETENTION_DAYS: invalid number of days %q", v)
+		}
+	}
+	cfg.Retention = time.Duration(days) * 24 * time.Hour
+
+	cfg.Interval = time.Hour
+	if v := os.Getenv("PURGE_INTERVAL"); v != "" {
+		if cfg.Interval, err = time.ParseDuration(v); err != nil || cfg.Interval <= 0 {
+			return cfg, fmt.Errorf("PURGE_INTERVAL: invalid duration %q", v)
+		}
+	}
+
+	return cfg, nil
+}
+
+type PurgeJob struct {
+	PurgeRepo repository.PurgeRepo
+	Config    PurgeConfig
+}
+
+// Run purges once immediately and then every interval until c is done.
+func (a *PurgeJob) Run(c context.Context) {
+	c = helper.WithActor(c, helper.Actor{Type: "system", ID: "purge"})
+
+	ticker := time.NewTicker(a.Config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if a.Config.Enabled {
+			res, err := a.PurgeRepo.PurgeAccounts(c, time.Now().Add(-a.Config.Retention), a.Config.Anonymize)
+			if err != nil {
+				log.Printf("purge deleted accounts: %v", err)
+			} else if res.Drivers+res.Passengers > 0 {
+				log.Printf("purged %d drivers and %d passengers", res.Drivers, res.Passengers)
+			}
+		}
+
+		if _, err := a.PurgeRepo.PurgeRevokedTokens(c, time.Now()); err != nil {
+			log.Printf("purge expired token revocations: %v", err)
+		}
+
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func NewPurgeJob(purgeRepo repository.PurgeRepo, config PurgeConfig) *PurgeJob {
+	return &PurgeJob{
+		PurgeRepo: purgeRepo,
+		Config:    config,
+	}
+}
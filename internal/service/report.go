@@ -0,0 +1,217 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+)
+
+// maxReportBuckets bounds the time series of a report, so an hourly report
+// over years cannot be asked for.
+const maxReportBuckets = 1000
+
+// ReportConfig holds the time zone reports are cut into days, weeks and
+// months in.
+type ReportConfig struct {
+	Location *time.Location
+}
+
+// ReportConfigFromEnv reads REPORT_TIMEZONE, an IANA zone name such as
+// "Asia/Makassar". It defaults to the local time zone of the server. The
+// database sums trips up per hour of UTC, or of the local time zone on
+// MySQL, so a zone whose offset is not a whole number of hours, or a server
+// whose local time zone is not, is refused.
+func ReportConfigFromEnv() (cfg ReportConfig, err error) {
+	cfg.Location = time.Local
+	if v := os.Getenv("REPORT_TIMEZONE"); v != "" {
+		if cfg.Location, err = time.LoadLocation(v); err != nil {
+			return cfg, fmt.Errorf("REPORT_TIMEZONE: unknown time zone %q", v)
+		}
+	}
+
+	for _, loc := range []*time.Location{cfg.Location, time.Local} {
+		if _, offset := time.Now().In(loc).Zone(); offset%3600 != 0 {
+			return cfg, fmt.Errorf("REPORT_TIMEZONE: %s is not a whole number of hours from UTC", loc)
+		}
+	}
+
+	return cfg, nil
+}
+
+// window resolves q against now. It reports false for an empty window.
+func (a ReportConfig) window(q dto.ReportQuery, now time.Time) (w dto.ReportWindow, ok bool) {
+	loc := a.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	w = dto.ReportWindow{
+		From:        a.in(q.From, loc),
+		To:          a.in(q.To, loc),
+		Granularity: q.Granularity,
+	}
+	if w.Granularity == "" {
+		w.Granularity = "day"
+	}
+
+	if w.To.IsZero() {
+		w.To = now.In(loc)
+	}
+	if w.From.IsZero() {
+		if q.Month > 0 {
+			w.From = w.To.AddDate(0, -q.Month, 0)
+		} else {
+			w.From = w.To.AddDate(0, 0, -30)
+		}
+	}
+
+	return w, w.From.Before(w.To)
+}
+
+// tooManyBuckets reports whether the time series of w would have more than
+// maxReportBuckets buckets.
+func tooManyBuckets(w dto.ReportWindow) bool {
+	buckets := 0
+	for start := helper.BucketStart(w.From, w.Granularity); start.Before(w.To); start = helper.NextBucket(start, w.Granularity) {
+		if buckets++; buckets > maxReportBuckets {
+			return true
+		}
+	}
+
+	return false
+}
+
+// comparedWindow is the window w is compared with. A window of whole months
+// is compared with as many whole months, one of whole days with as many days,
+// so the previous period of March is February and not the 31 days before.
+func comparedWindow(w dto.ReportWindow, compare string) dto.ReportWindow {
+	prev := w
+
+	switch {
+	case compare == "year":
+		prev.From, prev.To = w.From.AddDate(-1, 0, 0), w.To.AddDate(-1, 0, 0)
+	case isBucketStart(w.From, "month") && isBucketStart(w.To, "month"):
+		months := (w.To.Year()-w.From.Year())*12 + int(w.To.Month()-w.From.Month())
+		prev.From, prev.To = w.From.AddDate(0, -months, 0), w.From
+	case isBucketStart(w.From, "day") && isBucketStart(w.To, "day"):
+		days := 0
+		for day := w.From; day.Before(w.To); day = day.AddDate(0, 0, 1) {
+			days++
+		}
+		prev.From, prev.To = w.From.AddDate(0, 0, -days), w.From
+	default:
+		prev.From, prev.To = w.From.Add(-w.To.Sub(w.From)), w.From
+	}
+
+	return prev
+}
+
+func isBucketStart(t time.Time, granularity string) bool {
+	return helper.BucketStart(t, granularity).Equal(t)
+}
+
+// compareReports sets cur against prev. Routes are in the order of cur, then
+// the routes only prev has trips on.
+func compareReports(cur dto.Report, prev dto.Report, compare string) *dto.ReportComparison {
+	res := &dto.ReportComparison{
+		Compare: compare,
+		From:    prev.From,
+		To:      prev.To,
+		Common: dto.ReportChanges{
+			Trips:            change(int64(cur.Common.TotalTrip), int64(prev.Common.TotalTrip)),
+			Revenue:          change(cur.Common.TotalRevenue, prev.Common.TotalRevenue),
+			ActiveDrivers:    change(int64(cur.Common.ActiveDrivers), int64(prev.Common.ActiveDrivers)),
+			ActivePassengers: change(int64(cur.Common.ActivePassengers), int64(prev.Common.ActivePassengers)),
+		},
+		Routes: []dto.RouteChange{},
+	}
+
+	previous := map[string]dto.RoutesReport{}
+	for _, route := range prev.Trips {
+		previous[route.Route] = route
+	}
+
+	for _, route := range cur.Trips {
+		res.Routes = append(res.Routes, routeChange(route, previous[route.Route]))
+		delete(previous, route.Route)
+	}
+	for _, route := range prev.Trips {
+		if _, ok := previous[route.Route]; ok {
+			res.Routes = append(res.Routes, routeChange(dto.RoutesReport{Route: route.Route}, route))
+		}
+	}
+
+	return res
+}
+
+func routeChange(cur dto.RoutesReport, prev dto.RoutesReport) dto.RouteChange {
+	return dto.RouteChange{
+		Route: cur.Route,
+		ReportChanges: dto.ReportChanges{
+			Trips:            change(int64(cur.Total), int64(prev.Total)),
+			Revenue:          change(cur.Revenue, prev.Revenue),
+			ActiveDrivers:    change(int64(cur.ActiveDrivers), int64(prev.ActiveDrivers)),
+			ActivePassengers: change(int64(cur.ActivePassengers), int64(prev.ActivePassengers)),
+		},
+	}
+}
+
+func change(cur int64, prev int64) dto.Change {
+	res := dto.Change{Previous: prev, Delta: cur - prev}
+	if prev != 0 {
+		percent := math.Round(float64(res.Delta)/float64(prev)*10000) / 100
+		res.Percent = &percent
+	}
+
+	return res
+}
+
+// rankDrivers orders drivers by field, revenue if it is empty, and numbers
+// them from 1. Ties go to the lower driver ID.
+func rankDrivers(drivers []dto.DriverEarnings, field string, order string) {
+	key := map[string]func(d dto.DriverEarnings) float64{
+		"trips":          func(d dto.DriverEarnings) float64 { return float64(d.Trips) },
+		"revenue":        func(d dto.DriverEarnings) float64 { return float64(d.Revenue) },
+		"average_fare":   func(d dto.DriverEarnings) float64 { return d.AverageFare },
+		"active_days":    func(d dto.DriverEarnings) float64 { return float64(d.ActiveDays) },
+		"average_rating": func(d dto.DriverEarnings) float64 { return d.AverageRating },
+	}[field]
+	if key == nil {
+		key = func(d dto.DriverEarnings) float64 { return float64(d.Revenue) }
+	}
+
+	sort.SliceStable(drivers, func(i, j int) bool {
+		a, b := key(drivers[i]), key(drivers[j])
+		if a == b {
+			return drivers[i].DriverID < drivers[j].DriverID
+		}
+		if order == "asc" {
+			return a < b
+		}
+		return a > b
+	})
+
+	for i := range drivers {
+		drivers[i].Rank = i + 1
+	}
+}
+
+// in moves t into loc. Plain dates are parsed as midnight local time, see
+// helper.ParseTime, and are taken as midnight in loc instead.
+func (a ReportConfig) in(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+
+	if t.Location() == time.Local {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+
+	return t.In(loc)
+}
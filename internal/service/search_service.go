@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/dto"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/helper"
+	"github.com/GabrielMoody/mikronet-dashboard-service/internal/repository"
+)
+
+type SearchService interface {
+	Search(c context.Context, q dto.SearchQuery) (res dto.SearchResults, err *helper.ErrorStruct)
+}
+
+type SearchServiceImpl struct {
+	SearchRepo repository.SearchRepo
+}
+
+func (a *SearchServiceImpl) Search(c context.Context, q dto.SearchQuery) (res dto.SearchResults, err *helper.ErrorStruct) {
+	q.Q = strings.TrimSpace(q.Q)
+
+	if errValidate := helper.Validate.Struct(q); errValidate != nil {
+		return res, &helper.ErrorStruct{
+			Code: http.StatusBadRequest,
+			Err:  errValidate,
+		}
+	}
+
+	if q.Limit == 0 {
+		q.Limit = 10
+	}
+
+	groups := []struct {
+		entity string
+		hits   *[]dto.SearchHit
+	}{
+		{"driver", &res.Drivers},
+		{"passenger", &res.Passengers},
+		{"route", &res.Routes},
+		{"review", &res.Reviews},
+	}
+
+	for _, group := range groups {
+		*group.hits = []dto.SearchHit{}
+
+		if q.Type != "" && q.Type != group.entity {
+			continue
+		}
+
+		hits, errRepo := a.SearchRepo.Search(c, group.entity, q.Q, q.Limit)
+		if errRepo != nil {
+			return res, &helper.ErrorStruct{
+				Code: http.StatusInternalServerError,
+				Err:  errRepo,
+			}
+		}
+
+		*group.hits = append(*group.hits, hits...)
+	}
+
+	return res, nil
+}
+
+func NewSearchService(searchRepo repository.SearchRepo) SearchService {
+	return &SearchServiceImpl{
+		SearchRepo: searchRepo,
+	}
+}